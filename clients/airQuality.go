@@ -0,0 +1,93 @@
+package clients
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+/*
+GetAirQuality Gets the current air quality at a location from cache, or from the air quality API
+*/
+var GetAirQuality = func(ctx context.Context, latitude float64, longitude float64) (*utils.AirQuality, error) {
+
+	// Defines a key for cache based on lat and long
+	cacheKey := fmt.Sprintf("AirQuality_%f_%f", latitude, longitude)
+
+	// Checks if there is cached data
+	var airQuality utils.AirQuality
+	if err := database.GetCachedData(cacheKey, &airQuality); err == nil {
+		fmt.Printf("Cache hit for key: %s\n", cacheKey)
+		// Trigger webhook event for cache hit
+		if webhookTrigger != nil {
+			webhookTrigger.TriggerWebhooks("CACHE_HIT", fmt.Sprintf("LAT:%f, LONG:%f", latitude, longitude))
+		}
+		return &airQuality, nil
+	}
+	fmt.Printf("Cache miss for key: %s\n", cacheKey)
+
+	result, err := RequestAirQuality(ctx, latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the retrieved data
+	if err := database.SetCacheEntry(cacheKey, result); err != nil {
+		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
+	}
+
+	return result, nil
+}
+
+/*
+RequestAirQuality Calls the configured air quality API, without the cache, and normalizes the current values
+*/
+func RequestAirQuality(ctx context.Context, latitude float64, longitude float64) (*utils.AirQuality, error) {
+
+	// Construct the URL for the API call, with the time in the local time zone of the location
+	url := fmt.Sprintf("%s?latitude=%f&longitude=%f&timezone=auto&current=pm2_5,pm10,ozone,european_aqi",
+		config.AirQualityURL, latitude, longitude)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create air quality request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch air quality data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle HTTP errors from external API
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("air quality API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var response utils.AirQualityResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	// Ensure data is available
+	if response.Current.Time == "" {
+		return nil, fmt.Errorf("air quality API returned no current values")
+	}
+
+	return &utils.AirQuality{
+		Time:        response.Current.Time,
+		PM25:        response.Current.PM25,
+		PM10:        response.Current.PM10,
+		Ozone:       response.Current.Ozone,
+		EuropeanAQI: response.Current.EuropeanAQI,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package clients
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+/*
+GetClimateNormal Gets the historical mean temperature at a location for the calendar window from start to end,
+averaged over the given number of past years. The history changes rarely, so it is cached with a long TTL.
+*/
+var GetClimateNormal = func(ctx context.Context, latitude float64, longitude float64, start time.Time, end time.Time, years int) (*utils.ClimateNormal, error) {
+
+	// The window is identified by its calendar days, so the normal is reused for the whole cache period
+	cacheKey := fmt.Sprintf("Climate_%f_%f_%s_%s_%d", latitude, longitude, start.Format("01-02"), end.Format("01-02"), years)
+
+	var normal utils.ClimateNormal
+	if err := database.GetCachedData(cacheKey, &normal); err == nil {
+		fmt.Printf("Cache hit for key: %s\n", cacheKey)
+		// Trigger webhook event for cache hit
+		if webhookTrigger != nil {
+			webhookTrigger.TriggerWebhooks("CACHE_HIT", fmt.Sprintf("LAT:%f, LONG:%f", latitude, longitude))
+		}
+		return &normal, nil
+	}
+	fmt.Printf("Cache miss for key: %s\n", cacheKey)
+
+	// Collect the days of the window in every past year
+	windowDays := make(map[string]bool)
+	for year := 1; year <= years; year++ {
+		for day := start.AddDate(-year, 0, 0); !day.After(end.AddDate(-year, 0, 0)); day = day.AddDate(0, 0, 1) {
+			windowDays[day.Format(time.DateOnly)] = true
+		}
+	}
+
+	// One request covers the windows of all years, from the oldest start to the latest end
+	url := fmt.Sprintf("%s?latitude=%f&longitude=%f&start_date=%s&end_date=%s&timezone=auto&daily=temperature_2m_mean",
+		config.OPENMETEO_ARCHIVE, latitude, longitude,
+		start.AddDate(-years, 0, 0).Format(time.DateOnly), end.AddDate(-1, 0, 0).Format(time.DateOnly))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch archive data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle HTTP errors from external API
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenMeteo archive API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var archive utils.ArchiveResponse
+	if err := json.Unmarshal(body, &archive); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	// Average the days that fall within a window
+	var temperatures []float64
+	for i, date := range archive.Daily.Time {
+		if windowDays[date] {
+			appendValue(&temperatures, valueAt(archive.Daily.Temperature, i))
+		}
+	}
+
+	normal = utils.ClimateNormal{
+		StartDate:       start.Format(time.DateOnly),
+		EndDate:         end.Format(time.DateOnly),
+		Years:           years,
+		Days:            len(temperatures),
+		MeanTemperature: averageOrNil(temperatures),
+	}
+
+	// Cache the normal for a long time, as the history does not change
+	if err := database.SetCacheEntryWithTTL(cacheKey, normal, config.CLIMATE_CACHE_EXPIRATION); err != nil {
+		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
+	}
+
+	return &normal, nil
+}
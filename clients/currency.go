@@ -4,10 +4,15 @@ import (
 	"assignment-2/config"
 	"assignment-2/database"
 	"assignment-2/utils"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"os"
+	"strings"
 )
 
 type WebhookTrigger interface {
@@ -21,29 +26,151 @@ func SetClientWebhookTrigger(trigger WebhookTrigger) {
 }
 
 /*
-GetCurrencyRates Retrieves the currency API result from cache or the external API
+CurrencySource A provider of exchange rates from a base currency
 */
-var GetCurrencyRates = func(currency []string, countryCode string) (*utils.CurrencyAPIResult, error) {
-	// Create a unique cache key via the country code
-	cacheKey := fmt.Sprintf("currency_%s", countryCode)
+type CurrencySource interface {
+	Name() string
+	Rates(ctx context.Context, base string) (*utils.RateTable, error)
+}
+
+// currencySources holds the available currency sources by name
+var currencySources = map[string]CurrencySource{
+	config.CURRENCY_CAMPUS:    exchangeRateSource{name: config.CURRENCY_CAMPUS, root: config.CURRENCY_ROOT},
+	config.CURRENCY_OPENERAPI: exchangeRateSource{name: config.CURRENCY_OPENERAPI, root: config.OPENERAPI_ROOT},
+	config.CURRENCY_STATIC:    staticRateSource{path: config.StaticRatesFile},
+}
+
+/*
+GetCurrencySources Returns the configured currency sources in fallback order
+*/
+func GetCurrencySources() ([]CurrencySource, error) {
+	var sources []CurrencySource
+	for _, name := range config.CurrencySources {
+		source, ok := currencySources[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown currency source %s", name)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
 
+/*
+GetCurrencyRates Retrieves the rates from a base currency to the requested currencies. The configured
+sources are tried in order, and a later source is only asked for the codes the earlier ones failed to
+supply. Every rate records the source it came from, and the rates freshly fetched from an online source are kept
+in the rate history.
+*/
+var GetCurrencyRates = func(ctx context.Context, currency []string, countryCode string) (*utils.CurrencyAPIResult, error) {
+	sources, err := GetCurrencySources()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rates found so far, and the codes still missing
+	found := make(map[string]utils.CurrencyResponse)
+	missing := currency
 	var result utils.CurrencyAPIResult
+	var sourceErrs []error
+	// Rates from the cache or the static file are not new observations, so only fresh ones are recorded
+	var fresh []utils.CurrencyResponse
+
+	for _, source := range sources {
+		if len(missing) == 0 {
+			break
+		}
+
+		table, err := source.Rates(ctx, countryCode)
+		if err != nil {
+			log.Printf("Currency source %s failed for %s: %v\n", source.Name(), countryCode, err)
+			sourceErrs = append(sourceErrs, fmt.Errorf("%s: %w", source.Name(), err))
+			// A cancelled request will fail at every source
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		// The first source that answers provides the update times of the result
+		if result.BaseCode == "" {
+			result.BaseCode = table.BaseCode
+			result.TimeLastUpdateUTC = table.TimeLastUpdateUTC
+			result.TimeNextUpdateUTC = table.TimeNextUpdateUTC
+		}
+
+		//extracts the currency rates based on the currency code
+		var stillMissing []string
+		for _, code := range missing {
+			rate, exists := table.Rates[code]
+			if !exists {
+				stillMissing = append(stillMissing, code)
+				continue
+			}
+			found[code] = utils.CurrencyResponse{Code: code, Rate: rate, Source: table.Source}
+			if table.Fresh {
+				fresh = append(fresh, found[code])
+			}
+		}
+		missing = stillMissing
+	}
+
+	if len(missing) > 0 {
+		if len(found) == 0 && len(sourceErrs) > 0 {
+			return nil, fmt.Errorf("all currency sources failed: %w", errors.Join(sourceErrs...))
+		}
+		return nil, fmt.Errorf("currency code %s not found in any currency source", strings.Join(missing, ", "))
+	}
+
+	// Keep the rates in the requested order
+	for _, code := range currency {
+		result.Rates = append(result.Rates, found[code])
+	}
+	recordRates(result.BaseCode, fresh)
+
+	return &result, nil
+}
+
+/*
+exchangeRateSource An online source answering in the exchangerate-api format, such as the campus-hosted
+currency service. Rate tables are cached per source and base currency.
+*/
+type exchangeRateSource struct {
+	name string
+	root string
+}
+
+func (s exchangeRateSource) Name() string {
+	return s.name
+}
+
+/*
+Rates Retrieves the rate table from cache or the external API
+*/
+func (s exchangeRateSource) Rates(ctx context.Context, base string) (*utils.RateTable, error) {
+	// Create a unique cache key via the source and the base currency
+	cacheKey := fmt.Sprintf("currency_%s_%s", s.name, base)
+
+	var table utils.RateTable
 
 	// Retrieve cached data
-	if err := database.GetCachedData(cacheKey, &result); err == nil {
+	if err := database.GetCachedData(cacheKey, &table); err == nil {
 		fmt.Printf("Cache hit for key: %s\n", cacheKey)
 		// Trigger webhook event for cache hit
 		if webhookTrigger != nil {
-			webhookTrigger.TriggerWebhooks("CACHE_HIT", countryCode)
+			webhookTrigger.TriggerWebhooks("CACHE_HIT", base)
 		}
-		return &result, nil
+		return &table, nil
 	}
-	fmt.Printf("Cache miss for key: %s", cacheKey)
+	fmt.Printf("Cache miss for key: %s\n", cacheKey)
 
 	// Build the API url
-	url := config.CURRENCY_ROOT + countryCode
+	url := s.root + base
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exchange rate request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch exchange rate data: %w", err)
 	}
@@ -59,46 +186,65 @@ var GetCurrencyRates = func(currency []string, countryCode string) (*utils.Curre
 		return nil, fmt.Errorf("failed to read API response: %w", err)
 	}
 
-	// Define response struct to match the JSON
-	var apiResponse struct {
-		BaseCode          string             `json:"base_code"`
-		TimeLastUpdateUTC string             `json:"time_last_update_utc"`
-		TimeNextUpdateUTC string             `json:"time_next_update_utc"`
-		Rates             map[string]float64 `json:"rates"`
+	if err := json.Unmarshal(body, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
+	if len(table.Rates) == 0 {
+		return nil, fmt.Errorf("API returned no rates for %s", base)
+	}
+	table.Source = s.name
+	table.Fresh = true
 
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	// Cache the result for future calls with the same key
+	if err := database.SetCacheEntry(cacheKey, table); err != nil {
+		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
 	}
 
-	var fullCurrencyData []utils.CurrencyResponse
+	return &table, nil
+}
 
-	//extracts the currency rates based on the currency code
-	for _, code := range currency {
-		rate, exists := apiResponse.Rates[code]
-		if !exists {
-			return nil, fmt.Errorf("currency code %s not found in API response", code)
-		}
+/*
+staticRateSource A local rates file in the exchangerate-api format, keeping dashboards working offline.
+Rates from other base currencies are computed as cross rates through the base of the file.
+*/
+type staticRateSource struct {
+	path string
+}
+
+func (s staticRateSource) Name() string {
+	return config.CURRENCY_STATIC
+}
 
-		//appends the currencycodes and rates
-		fullCurrencyData = append(fullCurrencyData, utils.CurrencyResponse{
-			Code: code,
-			Rate: rate,
-		})
+/*
+Rates Reads the rates file and converts it to the requested base currency
+*/
+func (s staticRateSource) Rates(ctx context.Context, base string) (*utils.RateTable, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static rates file: %w", err)
 	}
 
-	// Creating the result
-	result = utils.CurrencyAPIResult{
-		BaseCode:          apiResponse.BaseCode,
-		TimeLastUpdateUTC: apiResponse.TimeLastUpdateUTC,
-		TimeNextUpdateUTC: apiResponse.TimeNextUpdateUTC,
-		Rates:             fullCurrencyData,
+	var file utils.RateTable
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse static rates file: %w", err)
 	}
 
-	// Cache the result for future calls with the same key
-	if err := database.SetCacheEntry(cacheKey, result); err != nil {
-		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
+	baseRate, exists := file.Rates[base]
+	if !exists || baseRate == 0 {
+		return nil, fmt.Errorf("base currency %s not found in static rates file", base)
 	}
 
-	return &result, nil
+	// Cross rate: one unit of base is 1/baseRate units of the file base
+	rates := make(map[string]float64, len(file.Rates))
+	for code, rate := range file.Rates {
+		rates[code] = rate / baseRate
+	}
+
+	return &utils.RateTable{
+		Source:            s.Name(),
+		BaseCode:          base,
+		TimeLastUpdateUTC: file.TimeLastUpdateUTC,
+		TimeNextUpdateUTC: file.TimeNextUpdateUTC,
+		Rates:             rates,
+	}, nil
 }
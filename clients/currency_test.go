@@ -0,0 +1,200 @@
+package clients
+
+import (
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+replaceForTest Replaces a package-level variable, such as the currency sources, until the end of a test, so
+every test starts from the same state whatever order the tests run in
+*/
+func replaceForTest[T any](t testing.TB, target *T, value T) {
+	original := *target
+	*target = value
+	t.Cleanup(func() { *target = original })
+}
+
+/*
+fakeSource A currency source answering with fixed rates, or failing with an error
+*/
+type fakeSource struct {
+	name  string
+	rates map[string]float64
+	fresh bool
+	err   error
+}
+
+func (s fakeSource) Name() string {
+	return s.name
+}
+
+func (s fakeSource) Rates(ctx context.Context, base string) (*utils.RateTable, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &utils.RateTable{Source: s.name, BaseCode: base, Rates: s.rates, Fresh: s.fresh}, nil
+}
+
+/*
+useSources Makes the given sources the configured currency sources, in the given order
+*/
+func useSources(t *testing.T, sources ...CurrencySource) {
+	byName := make(map[string]CurrencySource)
+	var names []string
+	for _, source := range sources {
+		byName[source.Name()] = source
+		names = append(names, source.Name())
+	}
+	replaceForTest(t, &currencySources, byName)
+	replaceForTest(t, &config.CurrencySources, names)
+}
+
+/*
+TestGetCurrencyRates tests the fallback chain of the currency sources, expected result: a later source is only
+used for the codes the earlier ones failed to supply, every rate names its source, and the rates keep the
+requested order
+*/
+func TestGetCurrencyRates(t *testing.T) {
+	failing := fakeSource{name: "campus", err: errors.New("connection refused")}
+	campus := fakeSource{name: "campus", rates: map[string]float64{"EUR": 0.09, "USD": 0.1}}
+	open := fakeSource{name: "openerapi", rates: map[string]float64{"EUR": 0.08, "USD": 0.11, "ISK": 13}}
+	static := fakeSource{name: "static", rates: map[string]float64{"EUR": 0.07, "XAU": 0.00004}}
+
+	tests := []struct {
+		name     string
+		sources  []CurrencySource
+		codes    []string
+		expected []utils.CurrencyResponse
+		errorHas string
+	}{
+		{
+			name:     "first source has every code",
+			sources:  []CurrencySource{campus, open, static},
+			codes:    []string{"USD", "EUR"},
+			expected: []utils.CurrencyResponse{{Code: "USD", Rate: 0.1, Source: "campus"}, {Code: "EUR", Rate: 0.09, Source: "campus"}},
+		},
+		{
+			name:     "failing source falls back to the next",
+			sources:  []CurrencySource{failing, open, static},
+			codes:    []string{"EUR"},
+			expected: []utils.CurrencyResponse{{Code: "EUR", Rate: 0.08, Source: "openerapi"}},
+		},
+		{
+			name:    "missing codes fall back one by one",
+			sources: []CurrencySource{campus, open, static},
+			codes:   []string{"XAU", "EUR", "ISK"},
+			expected: []utils.CurrencyResponse{
+				{Code: "XAU", Rate: 0.00004, Source: "static"},
+				{Code: "EUR", Rate: 0.09, Source: "campus"},
+				{Code: "ISK", Rate: 13, Source: "openerapi"},
+			},
+		},
+		{
+			name:     "code in no source",
+			sources:  []CurrencySource{campus, open, static},
+			codes:    []string{"EUR", "ABC"},
+			errorHas: "currency code ABC not found",
+		},
+		{
+			name:     "every source fails",
+			sources:  []CurrencySource{failing, fakeSource{name: "static", err: errors.New("no file")}},
+			codes:    []string{"EUR"},
+			errorHas: "all currency sources failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useSources(t, test.sources...)
+			result, err := GetCurrencyRates(context.Background(), test.codes, "NOK")
+			if test.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errorHas) {
+					t.Fatalf("expected an error with %q, got %v", test.errorHas, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.BaseCode != "NOK" {
+				t.Errorf("expected base code NOK, got %s", result.BaseCode)
+			}
+			if len(result.Rates) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, result.Rates)
+			}
+			for i, rate := range result.Rates {
+				if rate != test.expected[i] {
+					t.Errorf("rate %d: expected %+v, got %+v", i, test.expected[i], rate)
+				}
+			}
+		})
+	}
+}
+
+/*
+TestGetCurrencyRatesCancelled tests a cancelled request, expected result: the context error, without asking the
+remaining sources
+*/
+func TestGetCurrencyRatesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	useSources(t, fakeSource{name: "campus", err: context.Canceled}, fakeSource{name: "static", rates: map[string]float64{"EUR": 0.07}})
+
+	if _, err := GetCurrencyRates(ctx, []string{"EUR"}, "NOK"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+/*
+TestStaticRateSource tests the rates file source, expected result: rates from the base of the file as they are,
+cross rates through the base of the file for any other base, and an error for a base not in the file
+*/
+func TestStaticRateSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "currency.json")
+	file := `{"base_code": "USD", "time_last_update_utc": "Mon, 17 Mar 2025 00:02:31 +0000", "rates": {"USD": 1, "NOK": 10, "EUR": 0.5}}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source := staticRateSource{path: path}
+
+	tests := []struct {
+		base     string
+		expected map[string]float64
+	}{
+		{"USD", map[string]float64{"USD": 1, "NOK": 10, "EUR": 0.5}},
+		{"NOK", map[string]float64{"USD": 0.1, "NOK": 1, "EUR": 0.05}},
+		{"EUR", map[string]float64{"USD": 2, "NOK": 20, "EUR": 1}},
+	}
+	for _, test := range tests {
+		table, err := source.Rates(context.Background(), test.base)
+		if err != nil {
+			t.Fatalf("%s: %v", test.base, err)
+		}
+		if table.BaseCode != test.base || table.Source != config.CURRENCY_STATIC || table.Fresh {
+			t.Errorf("%s: unexpected table %+v", test.base, table)
+		}
+		if table.TimeLastUpdateUTC != "Mon, 17 Mar 2025 00:02:31 +0000" {
+			t.Errorf("%s: expected the update time of the file, got %q", test.base, table.TimeLastUpdateUTC)
+		}
+		for code, rate := range test.expected {
+			if math.Abs(table.Rates[code]-rate) > 1e-9 {
+				t.Errorf("%s to %s: expected %v, got %v", test.base, code, rate, table.Rates[code])
+			}
+		}
+	}
+
+	if _, err := source.Rates(context.Background(), "ISK"); err == nil {
+		t.Error("expected an error for a base not in the file")
+	}
+	if _, err := (staticRateSource{path: filepath.Join(t.TempDir(), "missing.json")}).Rates(context.Background(), "USD"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
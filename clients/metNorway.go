@@ -0,0 +1,192 @@
+package clients
+
+import (
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+/*
+MetNorwayProvider Gets the weather forecast from the MET Norway locationforecast API
+*/
+type MetNorwayProvider struct{}
+
+func (MetNorwayProvider) Name() string {
+	return config.WEATHER_METNORWAY
+}
+
+/*
+Forecast calls the MET Norway API and aggregates its hourly time series into a daily forecast of the local days
+of the location. MET Norway forecasts about nine days ahead and has no sunrise or sunset, so those are left out.
+*/
+func (p MetNorwayProvider) Forecast(ctx context.Context, latitude float64, longitude float64, forecastDays int) (*utils.WeatherForecast, error) {
+
+	// MET Norway asks for coordinates with at most four decimals
+	url := fmt.Sprintf("%s?lat=%.4f&lon=%.4f", config.METNORWAY_ROOT, latitude, longitude)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weather request: %w", err)
+	}
+	// Requests without an identifying User-Agent are rejected
+	req.Header.Set("User-Agent", config.USER_AGENT)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle HTTP errors from external API
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("MET Norway API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var weatherData utils.MetNorwayResponse
+	if err := json.Unmarshal(body, &weatherData); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	// Ensure data is available
+	if len(weatherData.Properties.Timeseries) == 0 {
+		return nil, fmt.Errorf("MET Norway API returned no forecast")
+	}
+
+	// MET Norway answers in UTC, while the days of a forecast are local days of the location, as with Open-Meteo
+	location, err := time.LoadLocation(utils.NearestZone(latitude, longitude))
+	if err != nil {
+		location = time.UTC
+	}
+
+	forecast := utils.WeatherForecast{
+		Provider:  p.Name(),
+		Latitude:  latitude,
+		Longitude: longitude,
+		Daily:     metNorwayDays(weatherData, location, forecastDays),
+	}
+	return &forecast, nil
+}
+
+/*
+metNorwayDays Aggregates the hourly time series of MET Norway into at most the given number of days, grouping
+the entries by their date in the given location and keeping the days in time series order
+*/
+func metNorwayDays(weatherData utils.MetNorwayResponse, location *time.Location, forecastDays int) []utils.DailyForecast {
+	var dates []string
+	days := make(map[string]*metNorwayDay)
+	for _, entry := range weatherData.Properties.Timeseries {
+		instantTime, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil {
+			continue
+		}
+		date := instantTime.In(location).Format(time.DateOnly)
+		day, seen := days[date]
+		if !seen {
+			if len(dates) == forecastDays {
+				break
+			}
+			dates = append(dates, date)
+			day = &metNorwayDay{}
+			days[date] = day
+		}
+
+		instant := entry.Data.Instant.Details
+		appendValue(&day.temperatures, instant.AirTemperature)
+		appendValue(&day.humidity, instant.RelativeHumidity)
+		if instant.WindSpeed != nil {
+			// MET Norway gives the wind speed in m/s
+			day.windSpeeds = append(day.windSpeeds, *instant.WindSpeed*3.6)
+			if instant.WindFromDirection != nil {
+				// The dominant direction is the mean of the wind vectors
+				radians := *instant.WindFromDirection * math.Pi / 180
+				day.windX += *instant.WindSpeed * math.Sin(radians)
+				day.windY += *instant.WindSpeed * math.Cos(radians)
+				day.hasDirection = true
+			}
+		}
+
+		// The first days have hourly periods, later days only six hour periods
+		period := entry.Data.Next1Hours
+		if period == nil {
+			period = entry.Data.Next6Hours
+		}
+		if period != nil {
+			appendValue(&day.probabilities, period.Details.ProbabilityOfPrecipitation)
+			appendValue(&day.amounts, period.Details.PrecipitationAmount)
+		}
+	}
+
+	dailyForecasts := []utils.DailyForecast{}
+	for _, date := range dates {
+		day := days[date]
+		daily := utils.DailyForecast{
+			Date:                     date,
+			Temperature:              averageOrNil(day.temperatures),
+			PrecipitationProbability: averageOrNil(day.probabilities),
+			TemperatureMin:           Extreme(day.temperatures, math.Min),
+			TemperatureMax:           Extreme(day.temperatures, math.Max),
+			WindSpeedMax:             Extreme(day.windSpeeds, math.Max),
+			Humidity:                 averageOrNil(day.humidity),
+		}
+		if len(day.amounts) > 0 {
+			sum := 0.0
+			for _, amount := range day.amounts {
+				sum += amount
+			}
+			sum = math.Round(sum*100) / 100
+			daily.PrecipitationSum = &sum
+		}
+		if day.hasDirection {
+			direction := math.Mod(math.Atan2(day.windX, day.windY)*180/math.Pi+360, 360)
+			direction = math.Round(direction)
+			daily.WindDirection = &direction
+		}
+		dailyForecasts = append(dailyForecasts, daily)
+	}
+
+	return dailyForecasts
+}
+
+/*
+metNorwayDay collects the time series values of one day
+*/
+type metNorwayDay struct {
+	temperatures  []float64
+	humidity      []float64
+	windSpeeds    []float64
+	probabilities []float64
+	amounts       []float64
+	windX, windY  float64
+	hasDirection  bool
+}
+
+/*
+appendValue appends a value of the time series if it is present
+*/
+func appendValue(values *[]float64, value *float64) {
+	if value != nil {
+		*values = append(*values, *value)
+	}
+}
+
+/*
+averageOrNil returns the rounded average of the values, or nil if there are none
+*/
+func averageOrNil(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	average := Average(values)
+	return &average
+}
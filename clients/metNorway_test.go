@@ -0,0 +1,107 @@
+package clients
+
+import (
+	"assignment-2/utils"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// metNorwayFixture is a MET Norway time series in UTC: two hourly entries around midnight in Oslo, then two
+// entries with six hour periods
+const metNorwayFixture = `{"properties": {"timeseries": [
+	{"time": "2025-07-01T20:00:00Z", "data": {"instant": {"details": {"air_temperature": 11, "wind_speed": 5, "wind_from_direction": 90, "relative_humidity": 80}},
+		"next_1_hours": {"details": {"probability_of_precipitation": 20, "precipitation_amount": 0.5}}}},
+	{"time": "2025-07-01T22:00:00Z", "data": {"instant": {"details": {"air_temperature": 14, "wind_speed": 10, "wind_from_direction": 90, "relative_humidity": 60}},
+		"next_1_hours": {"details": {"probability_of_precipitation": 40, "precipitation_amount": 1}}}},
+	{"time": "2025-07-02T06:00:00Z", "data": {"instant": {"details": {"air_temperature": 20}},
+		"next_6_hours": {"details": {"probability_of_precipitation": 60, "precipitation_amount": 2}}}},
+	{"time": "2025-07-02T18:00:00Z", "data": {"instant": {"details": {"air_temperature": 17}},
+		"next_6_hours": {"details": {"probability_of_precipitation": 0, "precipitation_amount": 0}}}}
+]}}`
+
+/*
+TestMetNorwayDays tests the aggregation of the MET Norway time series, expected result: the entries are grouped
+by the local date of the location, as the days of Open-Meteo are, and at most the requested days are kept
+*/
+func TestMetNorwayDays(t *testing.T) {
+	var data utils.MetNorwayResponse
+	if err := json.Unmarshal([]byte(metNorwayFixture), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	type day struct {
+		date          string
+		mean          float64
+		min, max      float64
+		precipitation float64
+	}
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		zone      string
+		days      int
+		expected  []day
+	}{
+		{
+			name: "Oslo, two hours ahead of UTC", latitude: 59.91, longitude: 10.75, zone: "Europe/Oslo", days: 7,
+			expected: []day{{"2025-07-01", 11, 11, 11, 0.5}, {"2025-07-02", 17, 14, 20, 3}},
+		},
+		{
+			name: "Tokyo, nine hours ahead of UTC", latitude: 35.68, longitude: 139.69, zone: "Asia/Tokyo", days: 7,
+			expected: []day{{"2025-07-02", 15, 11, 20, 3.5}, {"2025-07-03", 17, 17, 17, 0}},
+		},
+		{
+			name: "Tokyo, one day", latitude: 35.68, longitude: 139.69, zone: "Asia/Tokyo", days: 1,
+			expected: []day{{"2025-07-02", 15, 11, 20, 3.5}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			zone := utils.NearestZone(test.latitude, test.longitude)
+			if zone != test.zone {
+				t.Fatalf("expected zone %s, got %s", test.zone, zone)
+			}
+			location, err := time.LoadLocation(zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			days := metNorwayDays(data, location, test.days)
+			if len(days) != len(test.expected) {
+				t.Fatalf("expected %d days, got %d", len(test.expected), len(days))
+			}
+			for i, expected := range test.expected {
+				got := days[i]
+				if got.Date != expected.date || *got.Temperature != expected.mean || *got.TemperatureMin != expected.min ||
+					*got.TemperatureMax != expected.max || *got.PrecipitationSum != expected.precipitation {
+					t.Errorf("day %d: expected %+v, got %s mean %v min %v max %v precipitation %v", i, expected,
+						got.Date, *got.Temperature, *got.TemperatureMin, *got.TemperatureMax, *got.PrecipitationSum)
+				}
+			}
+		})
+	}
+}
+
+/*
+TestMetNorwayDaysWind tests the wind of a MET Norway day, expected result: the highest speed converted from m/s
+to km/h, and the direction of the mean wind vector
+*/
+func TestMetNorwayDaysWind(t *testing.T) {
+	var data utils.MetNorwayResponse
+	if err := json.Unmarshal([]byte(metNorwayFixture), &data); err != nil {
+		t.Fatal(err)
+	}
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	days := metNorwayDays(data, oslo, 2)
+	if *days[1].WindSpeedMax != 36 || *days[1].WindDirection != 90 || *days[1].Humidity != 60 {
+		t.Errorf("expected wind 36 km/h from 90 at 60%% humidity, got %v from %v at %v",
+			*days[1].WindSpeedMax, *days[1].WindDirection, *days[1].Humidity)
+	}
+}
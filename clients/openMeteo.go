@@ -2,8 +2,8 @@ package clients
 
 import (
 	"assignment-2/config"
-	"assignment-2/database"
 	"assignment-2/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,31 +11,34 @@ import (
 	"net/http"
 )
 
+// openMeteoDaily lists the daily variables requested from Open-Meteo
+const openMeteoDaily = "temperature_2m_mean,precipitation_probability_mean,temperature_2m_min,temperature_2m_max," +
+	"precipitation_sum,wind_speed_10m_max,wind_direction_10m_dominant,relative_humidity_2m_mean,sunrise,sunset"
+
 /*
-GetWeatherDate calls the external API, OpenMeteo if the data is not available from cache
+OpenMeteoProvider Gets the weather forecast from the Open-Meteo API
 */
-var GetWeatherDate = func(latitude float64, longitude float64) (*utils.OpenMeteoresponse, error) {
+type OpenMeteoProvider struct{}
 
-	// Defines a key for cache based on lat and long
-	cacheKey := fmt.Sprintf("Openmeteo_%f_%f", latitude, longitude)
+func (OpenMeteoProvider) Name() string {
+	return config.WEATHER_OPENMETEO
+}
 
-	// Checks if there is cached data
-	var weatherData utils.OpenMeteoresponse
-	if err := database.GetCachedData(cacheKey, &weatherData); err == nil {
-		fmt.Printf("Cache hit for key: %s\n", cacheKey)
-		// Trigger webhook event for cache hit
-		if webhookTrigger != nil {
-			webhookTrigger.TriggerWebhooks("CACHE_HIT", fmt.Sprintf("LAT:%f, LONG:%f", latitude, longitude))
-		}
-		return &weatherData, nil
-	}
-	fmt.Printf("Cache miss for key: %s\n", cacheKey)
+/*
+Forecast calls the external API, OpenMeteo, and normalizes the daily forecast
+*/
+func (p OpenMeteoProvider) Forecast(ctx context.Context, latitude float64, longitude float64, days int) (*utils.WeatherForecast, error) {
 
-	// Construct the URL for the API call
-	url := fmt.Sprintf("%s?latitude=%f&longitude=%f&daily=temperature_2m_mean,precipitation_probability_mean", config.OPENMETEO_ROOT, latitude, longitude)
+	// Construct the URL for the API call, with the days in the local time zone of the location
+	url := fmt.Sprintf("%s?latitude=%f&longitude=%f&forecast_days=%d&timezone=auto&daily=%s",
+		config.OPENMETEO_ROOT, latitude, longitude, days, openMeteoDaily)
 
-	// Make the HTTP get request
-	resp, err := http.Get(url)
+	// Make the HTTP get request, bound to the context of the caller
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weather request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch weather data: %w", err)
 	}
@@ -53,21 +56,59 @@ var GetWeatherDate = func(latitude float64, longitude float64) (*utils.OpenMeteo
 	}
 
 	// Parse JSON response into weatherData variable
+	var weatherData utils.OpenMeteoresponse
 	if err := json.Unmarshal(body, &weatherData); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
 	// Ensure data is available
-	if len(weatherData.Daily.Precipitation) == 0 {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	if len(weatherData.Daily.Time) == 0 {
+		return nil, fmt.Errorf("OpenMeteo API returned no daily forecast")
 	}
 
-	// Cache the retireved data
-	if err := database.SetCacheEntry(cacheKey, weatherData); err != nil {
-		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
+	// Normalize the daily arrays into one entry per day
+	forecast := utils.WeatherForecast{
+		Provider:  p.Name(),
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+	for i, date := range weatherData.Daily.Time {
+		forecast.Daily = append(forecast.Daily, utils.DailyForecast{
+			Date:                     date,
+			Temperature:              valueAt(weatherData.Daily.Temperature, i),
+			PrecipitationProbability: valueAt(weatherData.Daily.Precipitation, i),
+			TemperatureMin:           valueAt(weatherData.Daily.TemperatureMin, i),
+			TemperatureMax:           valueAt(weatherData.Daily.TemperatureMax, i),
+			PrecipitationSum:         valueAt(weatherData.Daily.PrecipitationSum, i),
+			WindSpeedMax:             valueAt(weatherData.Daily.WindSpeedMax, i),
+			WindDirection:            valueAt(weatherData.Daily.WindDirection, i),
+			Humidity:                 valueAt(weatherData.Daily.Humidity, i),
+			Sunrise:                  stringAt(weatherData.Daily.Sunrise, i),
+			Sunset:                   stringAt(weatherData.Daily.Sunset, i),
+		})
 	}
 
-	return &weatherData, nil
+	return &forecast, nil
+}
+
+/*
+stringAt returns the string at an index of an Open-Meteo daily array, or an empty string if it is missing
+*/
+func stringAt(values []string, index int) string {
+	if index < len(values) {
+		return values[index]
+	}
+	return ""
+}
+
+/*
+valueAt returns the value at an index of an Open-Meteo daily array, or nil if it is missing
+*/
+func valueAt(values []*float64, index int) *float64 {
+	if index < len(values) {
+		return values[index]
+	}
+	return nil
 }
 
 /*
@@ -89,3 +130,17 @@ func Average(numbers []float64) float64 {
 	// Round to 2 decimal places
 	return math.Round(mean*100) / 100
 }
+
+/*
+Extreme returns the smallest or largest of a slice of float64 numbers, picked by pick, or nil if it is empty
+*/
+func Extreme(numbers []float64, pick func(a, b float64) float64) *float64 {
+	if len(numbers) == 0 {
+		return nil
+	}
+	result := numbers[0]
+	for _, num := range numbers[1:] {
+		result = pick(result, num)
+	}
+	return &result
+}
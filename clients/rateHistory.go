@@ -0,0 +1,27 @@
+package clients
+
+import (
+	"assignment-2/database"
+	"assignment-2/utils"
+	"log"
+	"time"
+)
+
+/*
+recordRates Stores freshly fetched rates from a base currency in the rate history in the background, one point
+of every rate for every fetch
+*/
+func recordRates(base string, rates []utils.CurrencyResponse) {
+	if len(rates) == 0 {
+		return
+	}
+	now := time.Now().UTC()
+	go func() {
+		for _, rate := range rates {
+			point := utils.RatePoint{Date: now.Format(time.DateOnly), Rate: rate.Rate, Source: rate.Source, Timestamp: now}
+			if err := database.AddRatePoint(base, rate.Code, point); err != nil {
+				log.Printf("Failed to store %s_%s in the rate history: %v\n", base, rate.Code, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"testing"
+	"time"
+)
+
+/*
+TestRecordRatesOnlyFresh tests which rates are stored in the rate history, expected result: the rates fetched
+from an online source are stored, the cached and static ones are not
+*/
+func TestRecordRatesOnlyFresh(t *testing.T) {
+	useSources(t,
+		fakeSource{name: "online", rates: map[string]float64{"EUR": 0.09}, fresh: true},
+		fakeSource{name: "cached", rates: map[string]float64{"USD": 0.1}},
+		fakeSource{name: "static", rates: map[string]float64{"SEK": 1.02}},
+	)
+	written := make(chan string, 10)
+	replaceForTest(t, &database.AddRatePoint, func(base string, target string, point utils.RatePoint) error {
+		written <- base + "_" + target
+		return nil
+	})
+
+	if _, err := GetCurrencyRates(context.Background(), []string{"EUR", "USD", "SEK"}, "NOK"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case pair := <-written:
+		if pair != "NOK_EUR" {
+			t.Errorf("expected NOK_EUR to be stored, got %s", pair)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the fresh rate to be stored")
+	}
+	select {
+	case pair := <-written:
+		t.Errorf("expected only the fresh rate to be stored, got %s", pair)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+/*
+TestRecordRatesEveryFetch tests repeated fetches on the same day, expected result: every fetch stores a point of
+its own, with the day and the time of that fetch
+*/
+func TestRecordRatesEveryFetch(t *testing.T) {
+	written := make(chan utils.RatePoint, 10)
+	replaceForTest(t, &database.AddRatePoint, func(base string, target string, point utils.RatePoint) error {
+		written <- point
+		return nil
+	})
+
+	var points []utils.RatePoint
+	for _, rate := range []float64{0.09, 0.091} {
+		recordRates("NOK", []utils.CurrencyResponse{{Code: "EUR", Rate: rate, Source: "online"}})
+		select {
+		case point := <-written:
+			points = append(points, point)
+		case <-time.After(time.Second):
+			t.Fatalf("expected the fetch of rate %v to be stored", rate)
+		}
+	}
+
+	if points[0].Rate != 0.09 || points[1].Rate != 0.091 {
+		t.Errorf("expected a point of every fetch, got %+v", points)
+	}
+	for _, point := range points {
+		if point.Timestamp.IsZero() || point.Date != point.Timestamp.Format(time.DateOnly) {
+			t.Errorf("expected the date of the fetch time, got %s at %s", point.Date, point.Timestamp)
+		}
+	}
+	if points[1].Timestamp.Before(points[0].Timestamp) {
+		t.Errorf("expected the second fetch to be stored after the first")
+	}
+}
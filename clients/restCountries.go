@@ -4,18 +4,20 @@ import (
 	"assignment-2/config"
 	"assignment-2/database"
 	"assignment-2/utils"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 )
 
 /*
 GetCountryData Retrieves data for countries by country name or ISO code.
-It attempts to load the data from cache. If it fails the external api is called.
+It attempts to load the data from cache. If it fails the external api is called, cancelled with ctx.
 */
-var GetCountryData = func(name string, isoCode string) (*utils.CountryResponse, error) {
+var GetCountryData = func(ctx context.Context, name string, isoCode string) (*utils.CountryResponse, error) {
 	var url string
 	var cacheKey string
 
@@ -49,8 +51,12 @@ var GetCountryData = func(name string, isoCode string) (*utils.CountryResponse,
 	}
 	fmt.Printf("Cache miss for key: %s\n", cacheKey)
 
-	// Calls the API
-	resp, err := http.Get(url)
+	// Calls the API, bound to the context of the caller
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create country request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch country data: %w", err)
 	}
@@ -84,3 +90,63 @@ var GetCountryData = func(name string, isoCode string) (*utils.CountryResponse,
 
 	return &countryData[0], nil
 }
+
+/*
+GetRegionCountries Retrieves every country of a REST Countries region, such as Europe, or subregion, such as
+Northern Europe. It attempts to load the data from cache. If it fails the external api is called, cancelled with ctx.
+*/
+var GetRegionCountries = func(ctx context.Context, kind string, name string) ([]utils.CountryResponse, error) {
+	if kind != "region" && kind != "subregion" {
+		return nil, fmt.Errorf("unknown region kind %s", kind)
+	}
+	url := fmt.Sprintf("%s%s/%s", config.RESTCOUNTRIES_ROOT, kind, neturl.PathEscape(name))
+	cacheKey := fmt.Sprintf("Country_%s_%s", kind, name)
+
+	var countries []utils.CountryResponse
+
+	// Tries to get a cache hit using the cache key
+	if err := database.GetCachedData(cacheKey, &countries); err == nil {
+		fmt.Printf("Cache hit for key: %s\n", cacheKey)
+		// Trigger webhook notification for the cache hit
+		if webhookTrigger != nil {
+			webhookTrigger.TriggerWebhooks("CACHE_HIT", name)
+		}
+		return countries, nil
+	}
+	fmt.Printf("Cache miss for key: %s\n", cacheKey)
+
+	// Calls the API, bound to the context of the caller
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create region request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch region data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle HTTP errors from external API
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("REST Countries API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error: Failed to read API response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &countries); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if len(countries) == 0 {
+		return nil, fmt.Errorf("no countries found in %s %s", kind, name)
+	}
+
+	// The retrieved result is cached
+	if err := database.SetCacheEntry(cacheKey, countries); err != nil {
+		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
+	}
+
+	return countries, nil
+}
@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"log"
+)
+
+/*
+WeatherProvider A weather API that delivers a daily forecast for a location in the normalized forecast model
+*/
+type WeatherProvider interface {
+	Name() string
+	// Forecast returns the daily forecast for the coming days, starting today
+	Forecast(ctx context.Context, latitude float64, longitude float64, days int) (*utils.WeatherForecast, error)
+}
+
+// weatherProviders holds the available weather providers by name
+var weatherProviders = map[string]WeatherProvider{
+	config.WEATHER_OPENMETEO: OpenMeteoProvider{},
+	config.WEATHER_METNORWAY: MetNorwayProvider{},
+}
+
+/*
+GetWeatherProvider Looks up a weather provider by its configured name
+*/
+func GetWeatherProvider(name string) (WeatherProvider, error) {
+	provider, ok := weatherProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown weather provider %s", name)
+	}
+	return provider, nil
+}
+
+/*
+GetWeatherDate Gets the forecast of the coming days for a location from cache, or from the configured
+weather provider. If the primary provider fails, the configured fallback provider is asked instead.
+*/
+var GetWeatherDate = func(ctx context.Context, latitude float64, longitude float64, days int) (*utils.WeatherForecast, error) {
+
+	// Defines a key for cache based on lat, long and the forecast horizon
+	cacheKey := fmt.Sprintf("Weather_%f_%f_%d", latitude, longitude, days)
+
+	// Checks if there is cached data
+	var weatherData utils.WeatherForecast
+	if err := database.GetCachedData(cacheKey, &weatherData); err == nil {
+		fmt.Printf("Cache hit for key: %s\n", cacheKey)
+		// Trigger webhook event for cache hit
+		if webhookTrigger != nil {
+			webhookTrigger.TriggerWebhooks("CACHE_HIT", fmt.Sprintf("LAT:%f, LONG:%f", latitude, longitude))
+		}
+		return &weatherData, nil
+	}
+	fmt.Printf("Cache miss for key: %s\n", cacheKey)
+
+	primary, err := GetWeatherProvider(config.WeatherProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := primary.Forecast(ctx, latitude, longitude, days)
+	if err != nil {
+		// Fail over to the secondary provider, unless it is disabled or the request was cancelled
+		if config.WeatherFallbackProvider == "none" || config.WeatherFallbackProvider == primary.Name() || ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("Weather provider %s failed, failing over to %s: %v\n", primary.Name(), config.WeatherFallbackProvider, err)
+
+		fallback, fallbackErr := GetWeatherProvider(config.WeatherFallbackProvider)
+		if fallbackErr != nil {
+			return nil, fallbackErr
+		}
+		forecast, fallbackErr = fallback.Forecast(ctx, latitude, longitude, days)
+		if fallbackErr != nil {
+			return nil, fmt.Errorf("all weather providers failed: %w, %w", err, fallbackErr)
+		}
+	}
+
+	// Cache the retrieved data
+	if err := database.SetCacheEntry(cacheKey, forecast); err != nil {
+		fmt.Printf("Failed to cache data for key %s: %v\n", cacheKey, err)
+	}
+
+	return forecast, nil
+}
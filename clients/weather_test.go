@@ -0,0 +1,118 @@
+package clients
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+/*
+fakeProvider A weather provider answering with an empty forecast of its own, or failing with an error, and
+counting how often it was asked
+*/
+type fakeProvider struct {
+	name  string
+	err   error
+	calls *int
+}
+
+func (p fakeProvider) Name() string {
+	return p.name
+}
+
+func (p fakeProvider) Forecast(ctx context.Context, latitude float64, longitude float64, days int) (*utils.WeatherForecast, error) {
+	*p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &utils.WeatherForecast{Provider: p.name, Latitude: latitude, Longitude: longitude}, nil
+}
+
+/*
+TestGetWeatherDateFailover tests the weather providers behind GetWeatherDate, expected result: the primary
+provider answers when it can, the fallback provider is only asked when the primary fails, and a disabled
+fallback or a cancelled request gives the error of the primary
+*/
+func TestGetWeatherDateFailover(t *testing.T) {
+	failure := errors.New("status 503")
+	tests := []struct {
+		name          string
+		primaryErr    error
+		fallbackErr   error
+		fallback      string
+		cancelled     bool
+		expected      string
+		errorHas      string
+		fallbackCalls int
+	}{
+		{name: "primary answers", fallback: "metno", expected: "openmeteo"},
+		{name: "primary fails", primaryErr: failure, fallback: "metno", expected: "metno", fallbackCalls: 1},
+		{name: "both fail", primaryErr: failure, fallbackErr: errors.New("status 429"), fallback: "metno", errorHas: "all weather providers failed", fallbackCalls: 1},
+		{name: "fallback disabled", primaryErr: failure, fallback: "none", errorHas: "status 503"},
+		{name: "fallback is the primary", primaryErr: failure, fallback: "openmeteo", errorHas: "status 503"},
+		{name: "request cancelled", primaryErr: context.Canceled, fallback: "metno", cancelled: true, errorHas: "canceled"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var primaryCalls, fallbackCalls int
+			replaceForTest(t, &weatherProviders, map[string]WeatherProvider{
+				"openmeteo": fakeProvider{name: "openmeteo", err: test.primaryErr, calls: &primaryCalls},
+				"metno":     fakeProvider{name: "metno", err: test.fallbackErr, calls: &fallbackCalls},
+			})
+			replaceForTest(t, &config.WeatherProvider, "openmeteo")
+			replaceForTest(t, &config.WeatherFallbackProvider, test.fallback)
+			replaceForTest(t, &database.GetCachedData, func(key string, dest interface{}) error {
+				return errors.New("not cached")
+			})
+			replaceForTest(t, &database.SetCacheEntry, func(key string, data interface{}) error { return nil })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancelled {
+				cancel()
+			}
+
+			forecast, err := GetWeatherDate(ctx, 59.91, 10.75, 3)
+			if test.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errorHas) {
+					t.Fatalf("expected an error with %q, got %v", test.errorHas, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if forecast.Provider != test.expected {
+				t.Errorf("expected a forecast from %s, got %s", test.expected, forecast.Provider)
+			}
+			if primaryCalls != 1 || fallbackCalls != test.fallbackCalls {
+				t.Errorf("expected 1 primary and %d fallback calls, got %d and %d", test.fallbackCalls, primaryCalls, fallbackCalls)
+			}
+		})
+	}
+}
+
+/*
+TestGetWeatherDateCached tests a cached forecast, expected result: the cached forecast, without asking any provider
+*/
+func TestGetWeatherDateCached(t *testing.T) {
+	var calls int
+	replaceForTest(t, &weatherProviders, map[string]WeatherProvider{
+		"openmeteo": fakeProvider{name: "openmeteo", calls: &calls},
+	})
+	replaceForTest(t, &config.WeatherProvider, "openmeteo")
+	replaceForTest(t, &database.GetCachedData, func(key string, dest interface{}) error {
+		*dest.(*utils.WeatherForecast) = utils.WeatherForecast{Provider: "cached"}
+		return nil
+	})
+
+	forecast, err := GetWeatherDate(context.Background(), 59.91, 10.75, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forecast.Provider != "cached" || calls != 0 {
+		t.Errorf("expected the cached forecast without provider calls, got %s after %d calls", forecast.Provider, calls)
+	}
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // The start url for the service
 const START_URL = "/dashboard/" + VERSION
 
@@ -8,8 +10,79 @@ const (
 	RESTCOUNTRIES_ROOT = "http://129.241.150.113:8080/v3.1/"
 	CURRENCY_ROOT      = "http://129.241.150.113:9090/currency/"
 	OPENMETEO_ROOT     = "https://api.open-meteo.com/v1/forecast"
+	METNORWAY_ROOT     = "https://api.met.no/weatherapi/locationforecast/2.0/complete"
+	OPENERAPI_ROOT     = "https://open.er-api.com/v6/latest/"
+	OPENMETEO_ARCHIVE  = "https://archive-api.open-meteo.com/v1/archive"
+	OPENMETEO_AIR      = "https://air-quality-api.open-meteo.com/v1/air-quality"
+)
+
+// Names of the currency exchange rate sources
+const (
+	CURRENCY_CAMPUS    = "campus"
+	CURRENCY_OPENERAPI = "openerapi"
+	CURRENCY_STATIC    = "static"
+)
+
+// Names of the weather providers
+const (
+	WEATHER_OPENMETEO = "openmeteo"
+	WEATHER_METNORWAY = "metno"
+)
+
+// Forecast horizon in days, used when a weather feature does not set one, and the longest supported
+const (
+	DEFAULT_FORECAST_DAYS = 7
+	MAX_FORECAST_DAYS     = 16
+)
+
+// Climate comparison: years of history averaged by default and at most, and how long a normal is cached
+const (
+	DEFAULT_CLIMATE_YEARS    = 10
+	MAX_CLIMATE_YEARS        = 30
+	CLIMATE_CACHE_EXPIRATION = 30 * 24 * time.Hour
+)
+
+// Formulas: the most a registration can have, and the longest expression
+const (
+	MAX_FORMULAS           = 20
+	MAX_FORMULA_EXPRESSION = 200
 )
 
+// WORLD_POPULATION is the world population used for the population share, the UN estimate for mid-2024
+const WORLD_POPULATION = 8_161_972_572
+
+// MAX_COMPARISON_COUNTRIES is the largest number of countries in one comparison registration
+const MAX_COMPARISON_COUNTRIES = 10
+
+// Region dashboards: the most ISO codes in one registration, and how many countries are fetched at once
+const (
+	MAX_REGION_COUNTRIES     = 100
+	REGION_FETCH_CONCURRENCY = 8
+)
+
+// NEIGHBOUR_FETCH_CONCURRENCY is how many bordering countries are looked up at once
+const NEIGHBOUR_FETCH_CONCURRENCY = 4
+
+// Charts: the default and allowed size of a chart image in pixels
+const (
+	DEFAULT_CHART_WIDTH  = 800
+	DEFAULT_CHART_HEIGHT = 600
+	MIN_CHART_SIZE       = 200
+	MAX_CHART_SIZE       = 2000
+)
+
+// GEOJSON_BUILD_CONCURRENCY is how many dashboards are built at once for the GeoJSON export
+const GEOJSON_BUILD_CONCURRENCY = 4
+
+// MAX_POINTS is the largest number of named weather points in one registration
+const MAX_POINTS = 25
+
+// USER_AGENT identifies the service to APIs that require it, such as MET Norway
+const USER_AGENT = "go-ing-nuclear-dashboard/" + VERSION + " github.com/iamk3v/assignment-2-cloud"
+
+// DASHBOARD_TIMEOUT is the overall deadline for fetching all upstream data of one dashboard
+const DASHBOARD_TIMEOUT = 15 * time.Second
+
 // API version
 const VERSION = "v1"
 
@@ -29,3 +102,10 @@ const (
 const PROJECT_ID = "assignment-2-279db"
 const DASHBOARD_COLLECTION = "dashboards"
 const NOTIFICATION_COLLECTION = "webhooks"
+const RATE_HISTORY_COLLECTION = "rateHistory"
+
+// RATE_HISTORY_POINTS is the subcollection of a base/target pair that holds its daily rates
+const RATE_HISTORY_POINTS = "points"
+
+// MAX_HISTORY_DAYS is the longest period of rate history served by /history
+const MAX_HISTORY_DAYS = 365
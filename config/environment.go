@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Weather providers, chosen by name through the environment
+var (
+	// WeatherProvider is the weather provider asked first
+	WeatherProvider = getEnv("WEATHER_PROVIDER", WEATHER_OPENMETEO)
+	// WeatherFallbackProvider is asked when the primary provider fails, disabled when set to "none"
+	WeatherFallbackProvider = getEnv("WEATHER_FALLBACK_PROVIDER", WEATHER_METNORWAY)
+)
+
+// Currency exchange rate sources
+var (
+	// CurrencySources are the rate sources in the order they are tried
+	CurrencySources = strings.Split(getEnv("CURRENCY_SOURCES", CURRENCY_CAMPUS+","+CURRENCY_OPENERAPI+","+CURRENCY_STATIC), ",")
+	// StaticRatesFile is the local rates file used by the static source, so dashboards work offline
+	StaticRatesFile = getEnv("STATIC_RATES_FILE", "stub-data/currency.json")
+)
+
+// AirQualityURL is the Open-Meteo compatible air quality API, which can be pointed at a local stub
+var AirQualityURL = getEnv("AIR_QUALITY_URL", OPENMETEO_AIR)
+
+// UpstreamConcurrency is how many upstream calls of one dashboard are made at once, 1 making them one after another
+var UpstreamConcurrency = getEnvInt("UPSTREAM_CONCURRENCY", 8)
+
+// RateHistoryRetentionDays is how many days of exchange rate history are kept before they are purged
+var RateHistoryRetentionDays = getEnvInt("RATE_HISTORY_RETENTION_DAYS", 90)
+
+/*
+getEnv Returns the value of an environment variable, or the fallback if it is not set
+*/
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+/*
+getEnvInt Returns the value of an environment variable as a positive integer, or the fallback if it is not set or invalid
+*/
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -15,6 +15,8 @@ type CacheEntry struct {
 	Key       string    `firestore:"key" json:"key"`
 	Data      string    `firestore:"data" json:"data"`
 	Timestamp time.Time `firestore:"timestamp" json:"timestamp"`
+	// ExpiresAt is set for entries with their own TTL, other entries expire after CacheExpiration
+	ExpiresAt time.Time `firestore:"expiresAt,omitempty" json:"expiresAt,omitempty"`
 }
 
 const (
@@ -54,7 +56,21 @@ func GetCacheEntry(key string) (*CacheEntry, error) {
 /*
 SetCacheEntry Caches data under a key
 */
-func SetCacheEntry(key string, data interface{}) error {
+var SetCacheEntry = func(key string, data interface{}) error {
+	return setCacheEntry(key, data, time.Time{})
+}
+
+/*
+SetCacheEntryWithTTL Caches data that changes rarely under a key, valid for its own ttl instead of CacheExpiration
+*/
+func SetCacheEntryWithTTL(key string, data interface{}, ttl time.Duration) error {
+	return setCacheEntry(key, data, time.Now().Add(ttl))
+}
+
+/*
+setCacheEntry Caches data under a key, expiring at expiresAt or after CacheExpiration if it is zero
+*/
+func setCacheEntry(key string, data interface{}, expiresAt time.Time) error {
 	// Marshal the provided data into JSON
 	bytes, err := json.Marshal(data)
 	if err != nil {
@@ -65,6 +81,7 @@ func SetCacheEntry(key string, data interface{}) error {
 		Key:       key,
 		Data:      string(bytes),
 		Timestamp: time.Now(),
+		ExpiresAt: expiresAt,
 	}
 	// Saving the cache entry to Firestore (can overwrite if it exists)
 	_, err = Client.Collection(cacheCollection).Doc(key).Set(Ctx, entry)
@@ -75,13 +92,16 @@ func SetCacheEntry(key string, data interface{}) error {
 IsCacheValid Checks if the cache is valid
 */
 func IsCacheValid(entry *CacheEntry) bool {
+	if !entry.ExpiresAt.IsZero() {
+		return time.Now().Before(entry.ExpiresAt)
+	}
 	return time.Since(entry.Timestamp) < CacheExpiration
 }
 
 /*
 GetCachedData Retrieves the cached data with a key and unmarshals it into dest
 */
-func GetCachedData(key string, dest interface{}) error {
+var GetCachedData = func(key string, dest interface{}) error {
 	entry, err := GetCacheEntry(key)
 	if err != nil {
 		return err
@@ -95,7 +115,8 @@ func GetCachedData(key string, dest interface{}) error {
 }
 
 /*
-PurgeExpiredCacheEntries Queries firestore for cache entries that have expired and deletes them
+PurgeExpiredCacheEntries Queries firestore for cache entries that have expired and deletes them. Entries
+older than CacheExpiration are candidates, but those with a longer TTL are kept until it has passed.
 */
 func PurgeExpiredCacheEntries(ctx context.Context) error {
 	// Calculate expiration time
@@ -116,6 +137,11 @@ func PurgeExpiredCacheEntries(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("there was an error iterating cache documents: %w", err)
 		}
+		// Entries with their own TTL are kept until it has passed
+		var entry CacheEntry
+		if err := doc.DataTo(&entry); err == nil && IsCacheValid(&entry) {
+			continue
+		}
 		// Delete expired cache documents
 		_, err = doc.Ref.Delete(ctx)
 		if err != nil {
@@ -39,7 +39,7 @@ func DeleteRegistration(id string) error {
 /*
 UpdateRegistration Updates a specific registration in Firestore by ID
 */
-func UpdateRegistration(id string, dash utils.DashboardPost) error {
+var UpdateRegistration = func(id string, dash utils.DashboardPost) error {
 
 	// Overwrite the document
 	_, err := Client.Collection(config.DASHBOARD_COLLECTION).Doc(id).Set(Ctx, dash)
@@ -80,7 +80,7 @@ var GetOneRegistration = func(id string) (*utils.Dashboard, error) {
 /*
 GetAllRegistrations Gets all currently stored registrations from Firestore
 */
-func GetAllRegistrations() ([]utils.Dashboard, error) {
+var GetAllRegistrations = func() ([]utils.Dashboard, error) {
 	// Iterator through all documents
 	iter := Client.Collection(config.DASHBOARD_COLLECTION).Documents(Ctx)
 	var allDashboards []utils.Dashboard
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"assignment-2/config"
+	"assignment-2/utils"
+	"cloud.google.com/go/firestore"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/api/iterator"
+	"time"
+)
+
+/*
+ratePair Returns the document of a base/target pair in the rate history, such as NOK_EUR
+*/
+func ratePair(base string, target string) *firestore.DocumentRef {
+	return Client.Collection(config.RATE_HISTORY_COLLECTION).Doc(base + "_" + target)
+}
+
+/*
+AddRatePoint Stores one fetched rate of a base/target pair, keyed by the time of the fetch so every fetch is kept
+*/
+var AddRatePoint = func(base string, target string, point utils.RatePoint) error {
+	pair := ratePair(base, target)
+	// The pair document itself is written too, so the pairs can be listed when purging
+	if _, err := pair.Set(Ctx, map[string]interface{}{"base": base, "target": target}); err != nil {
+		return err
+	}
+	_, err := pair.Collection(config.RATE_HISTORY_POINTS).Doc(point.Timestamp.UTC().Format(time.RFC3339Nano)).Set(Ctx, point)
+	return err
+}
+
+/*
+GetRateHistory Gets every stored rate of a base/target pair fetched from the given time on, oldest first
+*/
+var GetRateHistory = func(ctx context.Context, base string, target string, since time.Time) ([]utils.RatePoint, error) {
+	iter := ratePair(base, target).Collection(config.RATE_HISTORY_POINTS).
+		Where("timestamp", ">=", since).
+		OrderBy("timestamp", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	points := []utils.RatePoint{}
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("there was an error iterating rate history: %w", err)
+		}
+		var point utils.RatePoint
+		if err := doc.DataTo(&point); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+/*
+PurgeRateHistory Deletes the rates that are older than the configured retention from every base/target pair
+*/
+func PurgeRateHistory(ctx context.Context) error {
+	cutoff := time.Now().UTC().AddDate(0, 0, -config.RateHistoryRetentionDays)
+
+	pairs := Client.Collection(config.RATE_HISTORY_COLLECTION).DocumentRefs(ctx)
+	var purgeCounter int
+	for {
+		pair, err := pairs.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("there was an error iterating rate history pairs: %w", err)
+		}
+
+		iter := pair.Collection(config.RATE_HISTORY_POINTS).Where("timestamp", "<", cutoff).Documents(ctx)
+		for {
+			doc, err := iter.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				iter.Stop()
+				return fmt.Errorf("there was an error iterating rate history: %w", err)
+			}
+			if _, err := doc.Ref.Delete(ctx); err != nil {
+				iter.Stop()
+				return fmt.Errorf("failed to delete rate %s of %s: %w", doc.Ref.ID, pair.ID, err)
+			}
+			purgeCounter++
+		}
+		iter.Stop()
+	}
+	fmt.Printf("Purged %d exchange rates from the history\n", purgeCounter)
+	return nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/services"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+ChartHandler Handles requests sent to the /charts endpoint, routing the request to
+corresponding handle functions based on http methods.
+*/
+func ChartHandler(w http.ResponseWriter, r *http.Request) {
+	basePath := config.START_URL + "/charts/"
+	id := strings.Split(strings.TrimPrefix(r.URL.Path, basePath), "/")[0]
+
+	// Check if ID is provided
+	if id == "" {
+		http.Error(w, "Dashboard ID not provided", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		handleChartGetRequest(w, r, id)
+	default:
+		http.Error(w, "REST method '"+r.Method+"' not supported. "+
+			"Currently only '"+http.MethodGet+"' is supported.", http.StatusNotImplemented)
+		return
+	}
+}
+
+/*
+handleChartGetRequest Draws the weather and currency series of a registration as an SVG image, given as
+?series=temperature,precipitation,rates&width=800&height=600&days=7. Every parameter is optional.
+*/
+func handleChartGetRequest(w http.ResponseWriter, r *http.Request, id string) {
+	query := r.URL.Query()
+
+	width, err := chartSize(query.Get("width"), config.DEFAULT_CHART_WIDTH)
+	if err != nil {
+		http.Error(w, "Query parameter 'width' "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	height, err := chartSize(query.Get("height"), config.DEFAULT_CHART_HEIGHT)
+	if err != nil {
+		http.Error(w, "Query parameter 'height' "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	days := config.DEFAULT_FORECAST_DAYS
+	if rawDays := query.Get("days"); rawDays != "" {
+		days, err = strconv.Atoi(rawDays)
+		if err != nil || days < 1 || days > config.MAX_FORECAST_DAYS {
+			http.Error(w, "Query parameter 'days' must be a number from 1 to "+strconv.Itoa(config.MAX_FORECAST_DAYS), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Retrieve the dashboard configuration from firestore
+	reg, err := database.GetOneRegistration(id)
+	if err != nil {
+		log.Println("Error retrieving dashboard with id " + id + ": " + err.Error())
+		http.Error(w, "There was an error getting the dashboard with id: "+id, http.StatusInternalServerError)
+		return
+	}
+
+	series, err := services.ParseChartSeries(query.Get("series"), reg)
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// All upstream calls share the deadline of this request
+	ctx, cancel := context.WithTimeout(r.Context(), config.DASHBOARD_TIMEOUT)
+	defer cancel()
+
+	panels, err := services.BuildChartPanels(ctx, reg, series, days)
+	if err != nil {
+		log.Println("Error building chart of dashboard with id " + id + ": " + err.Error())
+		var upstreamErr *services.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			http.Error(w, "Failed to fetch "+string(upstreamErr.Upstream)+" data", upstreamErrorStatus(ctx))
+			return
+		}
+		http.Error(w, "There was an error building the chart of dashboard with id: "+id, http.StatusInternalServerError)
+		return
+	}
+
+	// Send the final response
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if _, err := w.Write(services.RenderChartSVG(panels, width, height)); err != nil {
+		log.Println("Error writing chart: " + err.Error())
+	}
+}
+
+/*
+chartSize Reads the width or height of a chart in pixels, using the default if it is left out
+*/
+func chartSize(raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	size, err := strconv.Atoi(raw)
+	if err != nil || size < config.MIN_CHART_SIZE || size > config.MAX_CHART_SIZE {
+		return 0, errors.New("must be a number from " + strconv.Itoa(config.MIN_CHART_SIZE) + " to " + strconv.Itoa(config.MAX_CHART_SIZE))
+	}
+	return size, nil
+}
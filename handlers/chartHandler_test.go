@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"assignment-2/clients"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/*
+TestChartHandler tests that the weather and currency series of a registration are drawn as a well-formed SVG image
+*/
+func TestChartHandler(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/charts/mock-id?width=640&height=480", nil)
+	rec := httptest.NewRecorder()
+
+	ChartHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "image/svg+xml" {
+		t.Errorf("Expected an SVG image, got %s", contentType)
+	}
+
+	body := rec.Body.String()
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("Expected well-formed SVG, got %v:\n%s", err, body)
+			}
+			break
+		}
+	}
+	for _, expected := range []string{`width="640" height="480"`, "Daily temperature (°C)", "Daily precipitation (mm)", "Exchange rates from NOK", "<path d=\"M"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the chart to contain %q", expected)
+		}
+	}
+}
+
+/*
+TestChartHandlerSeries tests that only the requested series are drawn, without fetching the others
+*/
+func TestChartHandlerSeries(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+	replaceForTest(t, &clients.GetCurrencyRates, func(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
+		t.Error("Expected no currency request for a temperature chart")
+		return mockGetCurrencyRates(ctx, targets, base)
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/charts/mock-id?series=temperature", nil)
+	rec := httptest.NewRecorder()
+
+	ChartHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "Daily precipitation") {
+		t.Error("Expected only the temperature panel")
+	}
+}
+
+/*
+TestChartHandlerInvalidQuery tests charts with an unknown series or a size out of bounds, expected result: bad request
+*/
+func TestChartHandlerInvalidQuery(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+
+	for _, query := range []string{"?series=wind", "?width=10", "?height=abc", "?days=40"} {
+		req := httptest.NewRequest("GET", "/dashboard/v1/charts/mock-id"+query, nil)
+		rec := httptest.NewRecorder()
+
+		ChartHandler(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"assignment-2/config"
+	"assignment-2/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+/*
+ConvertHandler routes HTTP requests to the appropriate conversion method handler
+*/
+func ConvertHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handleConvertGetRequest(w, r)
+	default:
+		http.Error(w, "REST method '"+r.Method+"' not supported. "+
+			"Currently only '"+http.MethodGet+"' is supported.", http.StatusNotImplemented)
+		return
+	}
+}
+
+/*
+handleConvertGetRequest Converts an amount from one currency into one or more target currencies,
+given as ?from=NOK&to=EUR,USD&amount=100. The amount is 1 if left out.
+*/
+func handleConvertGetRequest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from := query.Get("from")
+	if from == "" {
+		http.Error(w, "Missing query parameter 'from'", http.StatusBadRequest)
+		return
+	}
+	to, err := services.ParseCurrencyCodes(query.Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid query parameter 'to': "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	amount := 1.0
+	if rawAmount := query.Get("amount"); rawAmount != "" {
+		amount, err = strconv.ParseFloat(rawAmount, 64)
+		if err != nil {
+			http.Error(w, "Invalid query parameter 'amount': "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		// NaN and Inf parse as numbers, but can not be converted or encoded
+		if math.IsNaN(amount) || math.IsInf(amount, 0) {
+			http.Error(w, "Invalid query parameter 'amount': "+rawAmount+" is not a finite number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// The rates are fetched under the same deadline as a dashboard
+	ctx, cancel := context.WithTimeout(r.Context(), config.DASHBOARD_TIMEOUT)
+	defer cancel()
+
+	response, err := services.Convert(ctx, from, to, amount)
+	if err != nil {
+		var upstreamErr *services.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			log.Println("Error converting currency: " + err.Error())
+			http.Error(w, "Failed to fetch "+string(upstreamErr.Upstream)+" data", upstreamErrorStatus(ctx))
+			return
+		}
+		http.Error(w, "Invalid conversion: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Send the final response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println("Error encoding response: " + err.Error())
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+TestConvertHandler tests the conversion of an amount into the target currencies
+*/
+func TestConvertHandler(t *testing.T) {
+	var requestedBase string
+	replaceForTest(t, &clients.GetCurrencyRates, func(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
+		requestedBase = base
+		return mockGetCurrencyRates(ctx, targets, base)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, config.START_URL+"/convert/?from=nok&to=EUR,USD&amount=250", nil)
+	rec := httptest.NewRecorder()
+
+	ConvertHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if requestedBase != "NOK" {
+		t.Errorf("Expected rates from NOK, got %s", requestedBase)
+	}
+
+	var response utils.ConversionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if response.Amount != 250 || len(response.Conversions) != 2 {
+		t.Fatalf("Unexpected conversion: %+v", response)
+	}
+	if amount := response.Conversions[0].Amount; amount == nil || *amount != 22.5 {
+		t.Errorf("Expected 22.5 EUR, got %v", amount)
+	}
+	if amount := response.Conversions[1].Amount; amount == nil || *amount != 25 {
+		t.Errorf("Expected 25 USD, got %v", amount)
+	}
+}
+
+/*
+TestConvertHandlerInvalidCode tests that a malformed currency code is rejected, expected result: bad request
+*/
+func TestConvertHandlerInvalidCode(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, config.START_URL+"/convert/?from=NOK&to=EURO", nil)
+	rec := httptest.NewRecorder()
+
+	ConvertHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+/*
+TestConvertHandlerInvalidAmount tests that amounts that are not finite, negative or not numbers are rejected,
+expected result: bad request
+*/
+func TestConvertHandlerInvalidAmount(t *testing.T) {
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	for _, amount := range []string{"NaN", "Inf", "-Inf", "1e400", "-5", "ten"} {
+		req := httptest.NewRequest(http.MethodGet, config.START_URL+"/convert/?from=NOK&to=EUR&amount="+amount, nil)
+		rec := httptest.NewRecorder()
+
+		ConvertHandler(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Amount %s: expected status 400, got %d", amount, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package handlers
+
+import (
+	"assignment-2/utils"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Output formats of a dashboard
+const (
+	formatJSON     = "json"
+	formatCSV      = "csv"
+	formatXML      = "xml"
+	formatHTML     = "html"
+	formatMarkdown = "markdown"
+)
+
+// formatContentTypes holds the content type of every output format
+var formatContentTypes = map[string]string{
+	formatJSON:     "application/json",
+	formatCSV:      "text/csv; charset=utf-8",
+	formatXML:      "application/xml; charset=utf-8",
+	formatHTML:     "text/html; charset=utf-8",
+	formatMarkdown: "text/markdown; charset=utf-8",
+}
+
+// acceptedMediaTypes holds the output format of every media type accepted in an Accept header
+var acceptedMediaTypes = map[string]string{
+	"application/json": formatJSON,
+	"text/csv":         formatCSV,
+	"application/xml":  formatXML,
+	"text/xml":         formatXML,
+	"text/html":        formatHTML,
+	"text/markdown":    formatMarkdown,
+	"*/*":              formatJSON,
+	"application/*":    formatJSON,
+	"text/*":           formatHTML,
+}
+
+// errNotAcceptable is returned when an Accept header lists none of the output formats
+var errNotAcceptable = errors.New("none of the accepted media types is supported")
+
+// xmlName is the pattern of a key that can be used as an XML element name as it is
+var xmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+/*
+negotiateFormat Chooses the output format of a dashboard: the format query parameter if set, or else the
+most preferred media type of the Accept header that is supported. JSON is the default.
+*/
+func negotiateFormat(r *http.Request) (string, error) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		if format == "md" {
+			format = formatMarkdown
+		}
+		if _, exists := formatContentTypes[format]; !exists {
+			return "", fmt.Errorf("unknown format '%s', expected json, csv, xml, html or markdown", format)
+		}
+		return format, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, nil
+	}
+
+	type mediaRange struct {
+		format  string
+		quality float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, supported := acceptedMediaTypes[mediaType]
+		if !supported {
+			continue
+		}
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{format: format, quality: quality})
+		}
+	}
+	if len(ranges) == 0 {
+		return "", errNotAcceptable
+	}
+	// Equally preferred media types keep the order of the header
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	return ranges[0].format, nil
+}
+
+/*
+writeDashboard Encodes a dashboard in the negotiated format and sends it. Every format is generated from the
+JSON representation of the dashboard, so they all hold the same values.
+*/
+func writeDashboard(w http.ResponseWriter, format string, title string, dashboard interface{}) error {
+	encoded, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if format == formatJSON {
+		body = append(encoded, '\n')
+	} else {
+		tree, err := parseOrdered(encoded)
+		if err != nil {
+			return err
+		}
+		switch format {
+		case formatCSV:
+			body, err = renderCSV(tree)
+		case formatXML:
+			body, err = renderXML(tree)
+		case formatHTML:
+			body, err = renderHTML(title, tree)
+		case formatMarkdown:
+			body = renderMarkdown(title, tree)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.Header().Set("Vary", "Accept")
+	_, err = w.Write(body)
+	return err
+}
+
+/*
+orderedNode A decoded JSON value that keeps the order of its object keys: a scalar as its JSON text, an object
+or a list with its children. The key is the object key or list index of the node.
+*/
+type orderedNode struct {
+	key      string
+	scalar   string
+	isNull   bool
+	isList   bool
+	isObject bool
+	children []*orderedNode
+}
+
+/*
+parseOrdered Decodes a JSON document into a tree that keeps the order of the object keys
+*/
+func parseOrdered(encoded []byte) (*orderedNode, error) {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var parse func(key string) (*orderedNode, error)
+	parse = func(key string) (*orderedNode, error) {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		node := &orderedNode{key: key}
+		switch value := token.(type) {
+		case json.Delim:
+			node.isList = value == '['
+			node.isObject = value == '{'
+			for decoder.More() {
+				childKey := strconv.Itoa(len(node.children))
+				if !node.isList {
+					keyToken, err := decoder.Token()
+					if err != nil {
+						return nil, err
+					}
+					childKey = keyToken.(string)
+				}
+				child, err := parse(childKey)
+				if err != nil {
+					return nil, err
+				}
+				node.children = append(node.children, child)
+			}
+			// The closing delimiter
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+		case nil:
+			node.isNull = true
+		case string:
+			node.scalar = value
+		case json.Number:
+			node.scalar = value.String()
+		case bool:
+			node.scalar = strconv.FormatBool(value)
+		}
+		return node, nil
+	}
+	return parse("")
+}
+
+/*
+flatten Lists every scalar of a tree with its dotted path, such as features.capital.0, in document order.
+Empty objects and lists are left out, and null is an empty value.
+*/
+func flatten(tree *orderedNode) [][2]string {
+	var rows [][2]string
+	var walk func(path string, node *orderedNode)
+	walk = func(path string, node *orderedNode) {
+		if node.isList || node.isObject {
+			for _, child := range node.children {
+				childPath := child.key
+				if path != "" {
+					childPath = path + "." + child.key
+				}
+				walk(childPath, child)
+			}
+			return
+		}
+		rows = append(rows, [2]string{path, node.scalar})
+	}
+	walk("", tree)
+	return rows
+}
+
+/*
+renderCSV Renders a tree as a two-column CSV of every dotted path and its value
+*/
+func renderCSV(tree *orderedNode) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write([]string{"field", "value"}); err != nil {
+		return nil, err
+	}
+	for _, row := range flatten(tree) {
+		if err := writer.Write(row[:]); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+/*
+renderXML Renders a tree as an XML document under a dashboard element. Object keys become element names,
+list items are item elements, and a key that is not a valid element name is kept in a key attribute.
+*/
+func renderXML(tree *orderedNode) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+	encoder.Indent("", "  ")
+
+	var encode func(name string, node *orderedNode) error
+	encode = func(name string, node *orderedNode) error {
+		start := xml.StartElement{Name: xml.Name{Local: name}}
+		if !xmlName.MatchString(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+			start = xml.StartElement{
+				Name: xml.Name{Local: "value"},
+				Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+			}
+		}
+		if node.isNull {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "null"}, Value: "true"})
+		}
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if node.isList || node.isObject {
+			for _, child := range node.children {
+				childName := child.key
+				if node.isList {
+					childName = "item"
+				}
+				if err := encode(childName, child); err != nil {
+					return err
+				}
+			}
+		} else if node.scalar != "" {
+			if err := encoder.EncodeToken(xml.CharData(node.scalar)); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+
+	if err := encode("dashboard", tree); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes(), nil
+}
+
+// htmlPage is the self-contained page of a dashboard, with its styles inline and no external resources
+var htmlPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.7rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+td:first-child { font-family: ui-monospace, monospace; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead><tr><th>Field</th><th>Value</th></tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+/*
+renderHTML Renders a tree as a standalone HTML page with a table of every dotted path and its value
+*/
+func renderHTML(title string, tree *orderedNode) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := htmlPage.Execute(&buffer, struct {
+		Title string
+		Rows  [][2]string
+	}{Title: title, Rows: flatten(tree)})
+	return buffer.Bytes(), err
+}
+
+/*
+renderMarkdown Renders a tree as a Markdown table of every dotted path and its value, under a heading
+*/
+func renderMarkdown(title string, tree *orderedNode) []byte {
+	var buffer bytes.Buffer
+	writeMarkdownLine(&buffer, "# "+escapeMarkdown(title))
+	writeMarkdownLine(&buffer, "")
+	writeMarkdownLine(&buffer, "| Field | Value |")
+	writeMarkdownLine(&buffer, "| --- | --- |")
+	for _, row := range flatten(tree) {
+		writeMarkdownLine(&buffer, "| "+escapeMarkdown(row[0])+" | "+escapeMarkdown(row[1])+" |")
+	}
+	return buffer.Bytes()
+}
+
+/*
+writeMarkdownLine Writes one line of a Markdown document
+*/
+func writeMarkdownLine(w io.Writer, line string) {
+	_, _ = io.WriteString(w, line+"\n")
+}
+
+/*
+escapeMarkdown Escapes the characters that would break a Markdown table cell
+*/
+func escapeMarkdown(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+/*
+formatErrorStatus Returns the status code of a failed format negotiation: an Accept header without a supported
+media type is not acceptable, an unknown format parameter is a bad request
+*/
+func formatErrorStatus(err error) int {
+	if errors.Is(err, errNotAcceptable) {
+		return http.StatusNotAcceptable
+	}
+	return http.StatusBadRequest
+}
+
+/*
+dashboardTitle Returns the heading of a dashboard in the HTML and Markdown formats
+*/
+func dashboardTitle(dashboard interface{}) string {
+	switch d := dashboard.(type) {
+	case *utils.PopulatedDashboard:
+		return "Dashboard: " + d.Country
+	case *utils.ComparisonDashboard:
+		return "Comparison dashboard"
+	case *utils.RegionDashboard:
+		return "Region dashboard: " + d.Name
+	default:
+		return "Dashboard"
+	}
+}
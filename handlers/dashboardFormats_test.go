@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"assignment-2/clients"
+	"assignment-2/database"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/*
+TestDashboardFormats tests that a dashboard is sent in the format chosen by the format parameter or Accept header
+*/
+func TestDashboardFormats(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		contentType string
+		contains    string
+	}{
+		{"default", "", "", "application/json", `"population":5379475`},
+		{"csv parameter", "?format=csv", "", "text/csv", "features.population,5379475"},
+		{"xml header", "", "application/xml", "application/xml", "<population>5379475</population>"},
+		{"html header", "", "text/html,application/xhtml+xml;q=0.9", "text/html", "<td>features.population</td><td>5379475</td>"},
+		{"markdown parameter", "?format=md", "application/json", "text/markdown", "| features.population | 5379475 |"},
+		{"preferred by quality", "", "text/csv;q=0.5, text/markdown", "text/markdown", "| features.capital.0 | Oslo |"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id"+test.query, nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			DashboardHandler(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, test.contentType) {
+				t.Errorf("Expected content type %s, got %s", test.contentType, contentType)
+			}
+			if !strings.Contains(rec.Body.String(), test.contains) {
+				t.Errorf("Expected the body to contain %q, got:\n%s", test.contains, rec.Body.String())
+			}
+		})
+	}
+}
+
+/*
+TestDashboardXMLIsWellFormed tests that keys that are not element names, such as currency codes, give valid XML
+*/
+func TestDashboardXMLIsWellFormed(t *testing.T) {
+	tree, err := parseOrdered([]byte(`{"rates": {"1d": 0.5, "xmlKey": null}, "list": ["a<b", "c"]}`))
+	if err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+	body, err := renderXML(tree)
+	if err != nil {
+		t.Fatalf("Error rendering XML: %v", err)
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(string(body)))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("Expected well-formed XML, got %v:\n%s", err, body)
+			}
+			break
+		}
+	}
+	if !strings.Contains(string(body), `<value key="1d">0.5</value>`) {
+		t.Errorf("Expected the key 1d in an attribute, got:\n%s", body)
+	}
+}
+
+/*
+TestDashboardNotAcceptable tests an Accept header without a supported media type, expected result: not acceptable
+*/
+func TestDashboardNotAcceptable(t *testing.T) {
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	req.Header.Set("Accept", "image/png")
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusNotAcceptable {
+		t.Fatalf("Expected status 406, got %d", rec.Code)
+	}
+}
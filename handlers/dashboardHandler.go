@@ -1,16 +1,17 @@
 package handlers
 
 import (
-	"assignment-2/clients"
 	"assignment-2/config"
 	"assignment-2/database"
+	"assignment-2/services"
 	"assignment-2/utils"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
-	"time"
 )
 
 type WebhookTrigger interface {
@@ -52,10 +53,18 @@ func DashboardHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 /*
-handleDashGetRequest gets configuration, fetches external data and sends the dashboard response
+handleDashGetRequest gets configuration, fetches external data and sends the dashboard response.
+The upstream calls of the enabled features share one request-scoped deadline.
 */
 func handleDashGetRequest(w http.ResponseWriter, r *http.Request, id string) {
 
+	// Choose the output format before any work is done
+	format, err := negotiateFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), formatErrorStatus(err))
+		return
+	}
+
 	// Retrieve the dashboard configuration from firestore
 	reg, err := database.GetOneRegistration(id)
 	if err != nil {
@@ -64,128 +73,106 @@ func handleDashGetRequest(w http.ResponseWriter, r *http.Request, id string) {
 		return
 	}
 
-	// Extract fields from the registration
-	country := reg.Country
-	isoCode := reg.IsoCode
-	features := reg.Features
-
-	// Get country info from the REST Countries API
-	countryData, err := clients.GetCountryData(country, isoCode)
+	// Apply the overrides of this request to a copy, the stored registration is never changed
+	overrides, err := parseDashboardOverrides(r)
 	if err != nil {
-		log.Println("failed to fetch country data: " + err.Error())
-		http.Error(w, "Failed to fetch country data", http.StatusBadGateway)
-		return
-	}
-
-	currencyCode := []string{}
-	for code := range countryData.Currencies {
-		currencyCode = append(currencyCode, code)
-	}
-	// Check if no currency codes were found
-	if len(currencyCode) == 0 {
-		http.Error(w, "no currency codes found for country", http.StatusInternalServerError)
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Get weather info from the Open-Meteo API
-	weatherData, err := clients.GetWeatherDate(countryData.Latlng[0], countryData.Latlng[1])
+	requested, err := services.ApplyOverrides(reg, overrides)
 	if err != nil {
-		http.Error(w, "Failed to fetch weather data", http.StatusBadGateway)
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	tempAverage := clients.Average(weatherData.Daily.Temperature)
-	precAverage := clients.Average(weatherData.Daily.Precipitation)
-
-	// Assemble the features based on the configuration in the database
-	featuresMap := make(map[string]interface{})
-
-	if features.Capital {
-		featuresMap["capital"] = countryData.Capital
-	}
+	// All upstream calls share the deadline of this request
+	ctx, cancel := context.WithTimeout(r.Context(), config.DASHBOARD_TIMEOUT)
+	defer cancel()
 
-	if features.Coordinates {
-		featuresMap["coordinates"] = map[string]float64{
-			"latitude":  countryData.Latlng[0],
-			"longitude": countryData.Latlng[1],
+	// Fetch the external data and resolve the enabled features
+	response, err := buildDashboardResponse(ctx, r, requested)
+	if err != nil {
+		log.Println("Error building dashboard with id " + id + ": " + err.Error())
+		var upstreamErr *services.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			http.Error(w, "Failed to fetch "+string(upstreamErr.Upstream)+" data", upstreamErrorStatus(ctx))
+			return
 		}
+		http.Error(w, "There was an error building the dashboard with id: "+id, http.StatusInternalServerError)
+		return
 	}
 
-	if features.Population {
-		featuresMap["population"] = countryData.Population
+	// Trigger webhooks asynchronously, once for every country of a comparison
+	if webhookTrigger != nil {
+		if reg.Type == utils.RegistrationComparison {
+			for _, country := range reg.Countries {
+				go webhookTrigger.TriggerWebhooks("INVOKE", country.IsoCode)
+			}
+		} else {
+			go webhookTrigger.TriggerWebhooks("INVOKE", reg.IsoCode)
+		}
 	}
 
-	if features.Area {
-		featuresMap["area"] = countryData.Area
+	// Send the final response in the negotiated format
+	if err := writeDashboard(w, format, dashboardTitle(response), response); err != nil {
+		log.Println("Error encoding response: " + err.Error())
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
 	}
+}
 
-	if features.Temperature {
-		featuresMap["temperature"] = tempAverage
+/*
+parseDashboardOverrides Reads the overrides of a dashboard request: the comma separated fields to keep, the
+comma separated target currencies to add, and the units, locale and lang to show the dashboard in
+*/
+func parseDashboardOverrides(r *http.Request) (services.DashboardOverrides, error) {
+	query := r.URL.Query()
+	var overrides services.DashboardOverrides
+	for _, field := range strings.Split(query.Get("fields"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			overrides.Fields = append(overrides.Fields, field)
+		}
 	}
-
-	if features.Precipitation {
-		featuresMap["precipitation"] = precAverage
+	if query.Has("fields") && len(overrides.Fields) == 0 {
+		return overrides, errors.New("fields can not be empty")
 	}
 
-	if len(features.TargetCurrencies) > 0 {
-		for currency := range currencyCode {
-			//get currency data from the currency API
-			result, err := clients.GetCurrencyRates(features.TargetCurrencies, currencyCode[currency])
-			if err != nil {
-				http.Error(w, "Currency API failed", http.StatusBadGateway)
-				return
-			}
-			// Initialize if needed to avoid panic
-			if featuresMap["targetCurrencies"] == nil {
-				featuresMap["targetCurrencies"] = []utils.GroupedCurrencyResponse{}
-			}
-
-			existingGroups := featuresMap["targetCurrencies"].([]utils.GroupedCurrencyResponse)
-
-			groupExists := false
-
-			// Check if group exists, and if exits it becomes an array
-			for i, group := range existingGroups {
-				if group.BaseCode == result.BaseCode {
-					existingGroups[i].Rates = append(existingGroups[i].Rates, result.Rates...)
-					groupExists = true
-					break
-				}
-			}
-
-			//if group dosnt exist create feature dashboard
-			if !groupExists {
-				existingGroups = append(existingGroups, utils.GroupedCurrencyResponse{
-					BaseCode:               result.BaseCode,
-					TimeLastCurrencyUpdate: result.TimeLastUpdateUTC,
-					TimeNextCurrencyUpdate: result.TimeNextUpdateUTC,
-					Rates:                  result.Rates,
-				})
-			}
-
-			featuresMap["targetCurrencies"] = existingGroups
-		}
+	currencies, err := services.ParseCurrencyCodes(query.Get("currencies"))
+	if err != nil {
+		return overrides, err
 	}
-
-	// Building the response
-	response := map[string]interface{}{
-		"country":       country,
-		"isoCode":       isoCode,
-		"features":      featuresMap,
-		"lastRetrieval": time.Now().Local().String(),
+	overrides.Currencies = currencies
+	overrides.Display = utils.DisplaySetting{
+		Units:  query.Get("units"),
+		Locale: query.Get("locale"),
+		Lang:   query.Get("lang"),
 	}
+	return overrides, nil
+}
 
-	// Trigger webhooks asynchronously
-	if webhookTrigger != nil {
-		go webhookTrigger.TriggerWebhooks("INVOKE", isoCode)
+/*
+buildDashboardResponse Populates a registration by its type: one country, every country of a comparison,
+or the aggregates of a region, with the details of its countries on drill-down
+*/
+func buildDashboardResponse(ctx context.Context, r *http.Request, reg *utils.Dashboard) (interface{}, error) {
+	switch reg.Type {
+	case utils.RegistrationComparison:
+		return services.BuildComparison(ctx, reg)
+	case utils.RegistrationRegion:
+		return services.BuildRegion(ctx, reg, r.URL.Query().Get("drilldown") == "true")
+	default:
+		return services.BuildDashboard(ctx, reg)
 	}
+}
 
-	// Send the final response
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Println("Error encoding response: " + err.Error())
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+/*
+upstreamErrorStatus returns the status code for a failed upstream call, separating an
+exceeded dashboard deadline from an upstream that answered with an error
+*/
+func upstreamErrorStatus(ctx context.Context) int {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
 	}
+	return http.StatusBadGateway
 }
 
 /*
@@ -2,15 +2,31 @@ package handlers
 
 import (
 	"assignment-2/clients"
+	"assignment-2/config"
 	"assignment-2/database"
 	"assignment-2/utils"
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
 
+/*
+replaceForTest Replaces a package-level variable, such as a client function, until the end of a test or
+benchmark, so every test starts from the same mocks whatever order the tests run in
+*/
+func replaceForTest[T any](t testing.TB, target *T, value T) {
+	original := *target
+	*target = value
+	t.Cleanup(func() { *target = original })
+}
+
 /*
 sets a predefined database pull
 */
@@ -20,13 +36,13 @@ func mockGetOneRegistration(id string) (*utils.Dashboard, error) {
 		Country: "Norway",
 		IsoCode: "NO",
 		Features: utils.Features{
-			Capital:          true,
-			Coordinates:      true,
-			Population:       true,
-			Area:             true,
-			Temperature:      true,
-			Precipitation:    true,
-			TargetCurrencies: []string{"EUR", "USD"},
+			"capital":          true,
+			"coordinates":      true,
+			"population":       true,
+			"area":             true,
+			"temperature":      true,
+			"precipitation":    true,
+			"targetCurrencies": []string{"EUR", "USD"},
 		},
 		LastChange: time.Now().Format("20060102 15:04"),
 	}, nil
@@ -35,7 +51,7 @@ func mockGetOneRegistration(id string) (*utils.Dashboard, error) {
 /*
 sets predefined country data
 */
-func mockGetCountryData(country, iso string) (*utils.CountryResponse, error) {
+func mockGetCountryData(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
 	return &utils.CountryResponse{
 
 		Capital:    []string{"Oslo"},
@@ -57,22 +73,29 @@ func mockGetCountryData(country, iso string) (*utils.CountryResponse, error) {
 /*
 sets predefined weather data
 */
-func mockGetWeatherDate(lat float64, lon float64) (*utils.OpenMeteoresponse, error) {
-	return &utils.OpenMeteoresponse{
-		Daily: struct {
-			Temperature   []float64 `json:"temperature_2m_mean"`
-			Precipitation []float64 `json:"precipitation_probability_mean"`
-		}{
-			Temperature:   []float64{2.0, 3.0, 4.0},
-			Precipitation: []float64{10.0, 20.0, 30.0},
-		},
-	}, nil
+func mockGetWeatherDate(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+	temperatures := []float64{2.0, 3.0, 4.0}
+	precipitation := []float64{10.0, 20.0, 30.0}
+	minimums := []float64{-1.0, 0.5, 1.0}
+	maximums := []float64{5.0, 6.5, 8.0}
+
+	forecast := &utils.WeatherForecast{Provider: "mock", Latitude: lat, Longitude: lon}
+	for i := range temperatures {
+		forecast.Daily = append(forecast.Daily, utils.DailyForecast{
+			Date:                     time.Now().AddDate(0, 0, i).Format(time.DateOnly),
+			Temperature:              &temperatures[i],
+			PrecipitationProbability: &precipitation[i],
+			TemperatureMin:           &minimums[i],
+			TemperatureMax:           &maximums[i],
+		})
+	}
+	return forecast, nil
 }
 
 /*
 sets predefined weather data
 */
-func mockGetCurrencyRates(targets []string, base string) (*utils.CurrencyAPIResult, error) {
+func mockGetCurrencyRates(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
 	return &utils.CurrencyAPIResult{
 		BaseCode:          base,
 		TimeLastUpdateUTC: time.Now().Format(time.RFC3339),
@@ -89,10 +112,10 @@ TestDashboardHandler tests the populated dashboard functionality
 */
 func TestDashboardHandler(t *testing.T) {
 	// Patch actual functions with mocks
-	database.GetOneRegistration = mockGetOneRegistration
-	clients.GetCountryData = mockGetCountryData
-	clients.GetWeatherDate = mockGetWeatherDate
-	clients.GetCurrencyRates = mockGetCurrencyRates
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
 
 	// Create request
 	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
@@ -128,3 +151,1055 @@ func TestDashboardHandler(t *testing.T) {
 		t.Error("Expected targetCurrencies in features, got none")
 	}
 }
+
+/*
+TestDashboardOnlyCallsRequiredUpstreams tests that a capital-only dashboard of a country without
+currencies succeeds without calling the weather or currency APIs
+*/
+func TestDashboardOnlyCallsRequiredUpstreams(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:       id,
+			Country:  "Antarctica",
+			IsoCode:  "AQ",
+			Features: utils.Features{"capital": true},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		return &utils.CountryResponse{Capital: []string{}, Latlng: []float64{-90.0, 0.0}}, nil
+	})
+	replaceForTest(t, &clients.GetWeatherDate, func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+		t.Error("Expected no call to the weather API")
+		return mockGetWeatherDate(ctx, lat, lon, days)
+	})
+	replaceForTest(t, &clients.GetCurrencyRates, func(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
+		t.Error("Expected no call to the currency API")
+		return mockGetCurrencyRates(ctx, targets, base)
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+
+	features := body["features"].(map[string]interface{})
+	if _, ok := features["capital"]; !ok {
+		t.Error("Expected capital in features, got none")
+	}
+	if len(features) != 1 {
+		t.Errorf("Expected only the capital feature, got %v", features)
+	}
+}
+
+/*
+TestDashboardForecastHorizon tests that weather features are limited to their own forecast horizon
+*/
+func TestDashboardForecastHorizon(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:      id,
+			Country: "Norway",
+			IsoCode: "NO",
+			Features: utils.Features{
+				"dailyForecast":    map[string]interface{}{"enabled": true, "days": 2},
+				"temperatureRange": map[string]interface{}{"enabled": true},
+			},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	features := body["features"].(map[string]interface{})
+
+	daily := features["dailyForecast"].(map[string]interface{})
+	if days := daily["days"].([]interface{}); len(days) != 2 {
+		t.Errorf("Expected 2 forecast days, got %d", len(days))
+	}
+
+	temperatureRange := features["temperatureRange"].(map[string]interface{})
+	if temperatureRange["min"] != -1.0 || temperatureRange["max"] != 8.0 {
+		t.Errorf("Expected temperature range -1.0 to 8.0, got %v to %v", temperatureRange["min"], temperatureRange["max"])
+	}
+}
+
+/*
+TestDashboardCapitalWeatherLocation tests that the weather is looked up at the capital when chosen,
+and that the dashboard reports the location used
+*/
+func TestDashboardCapitalWeatherLocation(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:              id,
+			Country:         "Norway",
+			IsoCode:         "NO",
+			Features:        utils.Features{"temperature": true},
+			WeatherLocation: utils.WeatherLocation{Type: utils.LocationCapital},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		data, err := mockGetCountryData(ctx, country, iso)
+		data.CapitalInfo.Latlng = []float64{59.92, 10.75}
+		return data, err
+	})
+	var usedLatitude float64
+	replaceForTest(t, &clients.GetWeatherDate, func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+		usedLatitude = lat
+		return mockGetWeatherDate(ctx, lat, lon, days)
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if usedLatitude != 59.92 {
+		t.Errorf("Expected weather at the capital latitude 59.92, got %v", usedLatitude)
+	}
+
+	var body utils.PopulatedDashboard
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if body.WeatherLocation == nil || body.WeatherLocation.Type != utils.LocationCapital {
+		t.Errorf("Expected weather location %s, got %v", utils.LocationCapital, body.WeatherLocation)
+	}
+}
+
+/*
+TestDashboardNamedPoints tests that the weather features are returned for every named point,
+each looked up at its own coordinates
+*/
+func TestDashboardNamedPoints(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:       id,
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: utils.Features{"capital": true, "temperature": true},
+			Points: []utils.NamedPoint{
+				{Label: "Bergen", Latitude: 60.39, Longitude: 5.32},
+				{Label: "Tromsø", Latitude: 69.65, Longitude: 18.96},
+			},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body utils.PopulatedDashboard
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if len(body.Points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(body.Points))
+	}
+	if body.Points[1].Label != "Tromsø" {
+		t.Errorf("Expected second point Tromsø, got %s", body.Points[1].Label)
+	}
+	if _, ok := body.Points[0].Features["temperature"]; !ok {
+		t.Error("Expected temperature in point features, got none")
+	}
+	if _, ok := body.Points[0].Features["capital"]; ok {
+		t.Error("Expected no country features in point features")
+	}
+}
+
+/*
+TestDashboardClimateComparison tests the anomaly of the forecast against the historical mean
+*/
+func TestDashboardClimateComparison(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:       id,
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: utils.Features{"climateComparison": map[string]interface{}{"enabled": true, "years": 5}},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+	replaceForTest(t, &clients.GetClimateNormal, func(ctx context.Context, lat float64, lon float64, start time.Time, end time.Time, years int) (*utils.ClimateNormal, error) {
+		mean := 2.0
+		return &utils.ClimateNormal{
+			StartDate:       start.Format(time.DateOnly),
+			EndDate:         end.Format(time.DateOnly),
+			Years:           years,
+			Days:            years * 3,
+			MeanTemperature: &mean,
+		}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	comparison := body["features"].(map[string]interface{})["climateComparison"].(map[string]interface{})
+
+	if comparison["anomaly"] != 1.0 {
+		t.Errorf("Expected anomaly 1.0, got %v", comparison["anomaly"])
+	}
+	if comparison["anomalyPercent"] != 50.0 {
+		t.Errorf("Expected anomaly of 50 percent, got %v", comparison["anomalyPercent"])
+	}
+	if comparison["years"] != 5.0 {
+		t.Errorf("Expected 5 years, got %v", comparison["years"])
+	}
+}
+
+/*
+TestDashboardAirQuality tests the air quality feature against the stub-data fixture served offline
+*/
+func TestDashboardAirQuality(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../stub-data/air-quality.json")
+	}))
+	defer server.Close()
+
+	replaceForTest(t, &config.AirQualityURL, server.URL)
+
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{"airQuality": true}}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	// Skip the cache, so the request goes to the stub server
+	replaceForTest(t, &clients.GetAirQuality, clients.RequestAirQuality)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	airQuality := body["features"].(map[string]interface{})["airQuality"].(map[string]interface{})
+
+	if airQuality["pm2_5"] != 4.2 || airQuality["pm10"] != 6.8 || airQuality["ozone"] != 78.0 {
+		t.Errorf("Unexpected pollutant values: %v", airQuality)
+	}
+	if airQuality["europeanAqi"] != 32.0 || airQuality["level"] != "fair" {
+		t.Errorf("Expected European AQI 32 (fair), got %v (%v)", airQuality["europeanAqi"], airQuality["level"])
+	}
+}
+
+/*
+TestDashboardCountryFacts tests the country fact features against the REST Countries payload in stub-data
+*/
+func TestDashboardCountryFacts(t *testing.T) {
+	fixture, err := os.ReadFile("../stub-data/restcountries.json")
+	if err != nil {
+		t.Fatalf("Error reading fixture: %v", err)
+	}
+	var countries []utils.CountryResponse
+	if err := json.Unmarshal(fixture, &countries); err != nil {
+		t.Fatalf("Error decoding fixture: %v", err)
+	}
+
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{
+			"names": true, "region": true, "languages": true, "borders": true, "timezones": true, "callingCodes": true,
+			"topLevelDomains": true, "flag": true, "drivingSide": true, "gini": true, "unMember": true,
+		}}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		return &countries[0], nil
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features struct {
+			Names        map[string]string `json:"names"`
+			Region       map[string]string `json:"region"`
+			Borders      []string          `json:"borders"`
+			CallingCodes []string          `json:"callingCodes"`
+			Flag         map[string]string `json:"flag"`
+			DrivingSide  string            `json:"drivingSide"`
+			Gini         map[string]any    `json:"gini"`
+			UnMember     bool              `json:"unMember"`
+			Languages    map[string]string `json:"languages"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	facts := body.Features
+
+	if facts.Names["official"] != "Kingdom of Norway" || facts.Region["subregion"] != "Northern Europe" {
+		t.Errorf("Unexpected names or region: %v, %v", facts.Names, facts.Region)
+	}
+	if len(facts.Borders) != 3 || len(facts.CallingCodes) != 1 || facts.CallingCodes[0] != "+47" {
+		t.Errorf("Unexpected borders or calling codes: %v, %v", facts.Borders, facts.CallingCodes)
+	}
+	if facts.Flag["emoji"] != "🇳🇴" || facts.DrivingSide != "right" || !facts.UnMember {
+		t.Errorf("Unexpected flag, driving side or UN membership: %v, %s, %t", facts.Flag, facts.DrivingSide, facts.UnMember)
+	}
+	if facts.Gini["year"] != "2018" || facts.Gini["index"] != 27.6 {
+		t.Errorf("Expected Gini index 27.6 of 2018, got %v", facts.Gini)
+	}
+	if facts.Languages["nob"] != "Norwegian Bokmål" {
+		t.Errorf("Expected Norwegian Bokmål among the languages, got %v", facts.Languages)
+	}
+}
+
+/*
+TestDashboardDerivedMetrics tests the metrics derived from the country, currency and weather data
+*/
+func TestDashboardDerivedMetrics(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{
+			"targetCurrencies":     []string{"EUR", "USD"},
+			"populationDensity":    true,
+			"worldPopulationShare": true,
+			"normalisedRates":      true,
+			"temperatureScales":    true,
+		}}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features struct {
+			PopulationDensity    float64 `json:"populationDensity"`
+			WorldPopulationShare float64 `json:"worldPopulationShare"`
+			NormalisedRates      []struct {
+				BaseCode string                         `json:"baseCode"`
+				Rates    map[string]map[string]*float64 `json:"rates"`
+			} `json:"normalisedRates"`
+			TemperatureScales map[string]float64 `json:"temperatureScales"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	metrics := body.Features
+
+	if metrics.PopulationDensity != 13.97 {
+		t.Errorf("Expected population density 13.97, got %f", metrics.PopulationDensity)
+	}
+	if metrics.WorldPopulationShare != 0.0659 {
+		t.Errorf("Expected world population share 0.0659, got %f", metrics.WorldPopulationShare)
+	}
+	if metrics.TemperatureScales["celsius"] != 3.0 || metrics.TemperatureScales["fahrenheit"] != 37.4 {
+		t.Errorf("Expected 3 °C and 37.4 °F, got %v", metrics.TemperatureScales)
+	}
+	if len(metrics.NormalisedRates) != 1 || metrics.NormalisedRates[0].BaseCode != "NOK" {
+		t.Fatalf("Expected normalised rates from NOK, got %v", metrics.NormalisedRates)
+	}
+	usd := metrics.NormalisedRates[0].Rates["USD"]
+	if *usd["EUR"] != 1.1111 || *usd["USD"] != 1 {
+		t.Errorf("Expected 1.1111 USD per EUR and 1 per USD, got %v, %v", *usd["EUR"], *usd["USD"])
+	}
+}
+
+/*
+TestDashboardFormulas tests that formulas are computed from features, whether enabled or not
+*/
+func TestDashboardFormulas(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:       id,
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: utils.Features{"population": true, "targetCurrencies": []string{"EUR"}},
+			Formulas: []utils.Formula{
+				{Name: "perThousandKm2", Expression: "population / area * 1000"},
+				{Name: "euroCents", Expression: "rates.EUR * 100"},
+				{Name: "undefined", Expression: "population / (area - area)"},
+			},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features map[string]interface{} `json:"features"`
+		Formulas map[string]*float64    `json:"formulas"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+
+	if _, shown := body.Features["area"]; shown {
+		t.Errorf("Expected area to be used by the formula without being shown")
+	}
+	if value := body.Formulas["perThousandKm2"]; value == nil || math.Abs(*value-5379475/385207.0*1000) > 1e-6 {
+		t.Errorf("Unexpected perThousandKm2: %v", value)
+	}
+	if value := body.Formulas["euroCents"]; value == nil || math.Abs(*value-9) > 1e-9 {
+		t.Errorf("Expected euroCents 9, got %v", value)
+	}
+	if value, exists := body.Formulas["undefined"]; !exists || value != nil {
+		t.Errorf("Expected a division by zero to be null, got %v", value)
+	}
+}
+
+/*
+TestDashboardLocalTime tests that the local time follows the tz database zone of the country, including DST. The zone
+table and the zones are embedded, so the result does not depend on the files of the host
+*/
+func TestDashboardLocalTime(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{"localTime": true}}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		return &utils.CountryResponse{Cca2: "NO", Timezones: []string{"UTC+01:00"}}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features struct {
+			LocalTime []struct {
+				Zone      string `json:"zone"`
+				UTCOffset string `json:"utcOffset"`
+				DST       bool   `json:"dst"`
+			} `json:"localTime"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if len(body.Features.LocalTime) != 1 {
+		t.Fatalf("Expected one time zone, got %d", len(body.Features.LocalTime))
+	}
+	local := body.Features.LocalTime[0]
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatalf("Error loading zone: %v", err)
+	}
+	expectedOffset := time.Now().In(oslo).Format("-07:00")
+	if local.Zone != "Europe/Oslo" || local.UTCOffset != expectedOffset || local.DST != time.Now().In(oslo).IsDST() {
+		t.Errorf("Expected Europe/Oslo at %s, got %s at %s (DST %t)", expectedOffset, local.Zone, local.UTCOffset, local.DST)
+	}
+}
+
+/*
+TestDashboardBaseCurrencyAmount tests that a registration can set its own base currency and an amount to convert
+*/
+func TestDashboardBaseCurrencyAmount(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:       id,
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: utils.Features{"targetCurrencies": []string{"EUR", "USD"}},
+			Currency: utils.CurrencySetting{Base: "sek", Amount: 100},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features struct {
+			TargetCurrencies []utils.GroupedCurrencyResponse `json:"targetCurrencies"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	groups := body.Features.TargetCurrencies
+
+	if len(groups) != 1 || groups[0].BaseCode != "SEK" || groups[0].Amount != 100 {
+		t.Fatalf("Expected one group from SEK with amount 100, got %+v", groups)
+	}
+	if amount := groups[0].Rates[0].Amount; amount == nil || *amount != 9 {
+		t.Errorf("Expected 100 SEK to be 9 EUR, got %v", amount)
+	}
+}
+
+/*
+TestDashboardCurrencyTrend tests the change of the rates against the stored rate history
+*/
+func TestDashboardCurrencyTrend(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{
+			"targetCurrencies": []string{"EUR"},
+			"currencyTrend":    true,
+		}}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetCurrencyRates, func(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
+		return &utils.CurrencyAPIResult{BaseCode: base, Rates: []utils.CurrencyResponse{{Code: "EUR", Rate: 0.09}}}, nil
+	})
+	// Eight days of history, so the 30 day change is unknown
+	replaceForTest(t, &database.GetRateHistory, func(ctx context.Context, base string, target string, since time.Time) ([]utils.RatePoint, error) {
+		day := func(ago int) string { return time.Now().UTC().AddDate(0, 0, -ago).Format(time.DateOnly) }
+		return []utils.RatePoint{
+			{Date: day(8), Rate: 0.08},
+			{Date: day(7), Rate: 0.075},
+			{Date: day(1), Rate: 0.1},
+		}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features struct {
+			CurrencyTrend []struct {
+				BaseCode string                            `json:"baseCode"`
+				Rates    map[string]map[string]interface{} `json:"rates"`
+			} `json:"currencyTrend"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if len(body.Features.CurrencyTrend) != 1 {
+		t.Fatalf("Expected one base currency, got %d", len(body.Features.CurrencyTrend))
+	}
+	eur := body.Features.CurrencyTrend[0].Rates["EUR"]
+
+	if change, ok := eur["1d"].(map[string]interface{}); !ok || change["percent"] != -10.0 {
+		t.Errorf("Expected a 1 day change of -10%%, got %v", eur["1d"])
+	}
+	if change, ok := eur["7d"].(map[string]interface{}); !ok || change["percent"] != 20.0 {
+		t.Errorf("Expected a 7 day change of 20%%, got %v", eur["7d"])
+	}
+	if eur["30d"] != nil {
+		t.Errorf("Expected no 30 day change without history, got %v", eur["30d"])
+	}
+}
+
+/*
+TestDashboardComparison tests that a comparison lists every country and ranks them by their numeric features
+*/
+func TestDashboardComparison(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:        id,
+			Type:      utils.RegistrationComparison,
+			Countries: []utils.CountryRef{{IsoCode: "no"}, {IsoCode: "SE"}, {Country: "Denmark"}},
+			Features:  utils.Features{"population": true, "capital": true},
+		}, nil
+	})
+	populations := map[string]int{"no": 5379475, "SE": 10353442, "": 5831404}
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		return &utils.CountryResponse{Population: populations[iso], Capital: []string{country + iso}}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var comparison utils.ComparisonDashboard
+	if err := json.NewDecoder(rec.Body).Decode(&comparison); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+
+	if len(comparison.Countries) != 3 || comparison.Countries["NO"] == nil || comparison.Countries["Denmark"] == nil {
+		t.Fatalf("Expected NO, SE and Denmark in the comparison, got %v", comparison.Countries)
+	}
+	ranking := comparison.Rankings["population"]
+	expected := []string{"SE", "Denmark", "NO"}
+	if len(ranking) != len(expected) {
+		t.Fatalf("Expected %d countries in the population ranking, got %d", len(expected), len(ranking))
+	}
+	for i, entry := range ranking {
+		if entry.Country != expected[i] || entry.Rank != i+1 {
+			t.Errorf("Expected %s at rank %d, got %s at rank %d", expected[i], i+1, entry.Country, entry.Rank)
+		}
+	}
+	if _, ranked := comparison.Rankings["capital"]; ranked {
+		t.Errorf("Expected no ranking of the non-numeric capital feature")
+	}
+}
+
+/*
+TestDashboardRegion tests the aggregates of a region dashboard, and its per-country details on drill-down
+*/
+func TestDashboardRegion(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:     id,
+			Type:   utils.RegistrationRegion,
+			Region: utils.RegionSetting{Name: "Nordic", Subregion: "Northern Europe"},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetRegionCountries, func(ctx context.Context, kind string, name string) ([]utils.CountryResponse, error) {
+		if kind != "subregion" || name != "Northern Europe" {
+			t.Errorf("Expected the subregion Northern Europe, got %s %s", kind, name)
+		}
+		norway, _ := mockGetCountryData(ctx, "Norway", "NO")
+		norway.Cca2 = "NO"
+		norway.Name.Common = "Norway"
+		sweden, _ := mockMultiCurrencyCountry(ctx, "Sweden", "SE")
+		sweden.Cca2 = "SE"
+		sweden.Name.Common = "Sweden"
+		sweden.Latlng = []float64{62.0, 15.0}
+		sweden.Population = 10000000
+		sweden.Area = 450295
+		return []utils.CountryResponse{*norway, *sweden}, nil
+	})
+	// Norway has a mean of 3 °C and Sweden of 6 °C
+	replaceForTest(t, &clients.GetWeatherDate, func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+		temperature := 3.0
+		if lon == 15.0 {
+			temperature = 6.0
+		}
+		return &utils.WeatherForecast{Daily: []utils.DailyForecast{{Date: "2025-04-10", Temperature: &temperature}}}, nil
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id?drilldown=true", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var region utils.RegionDashboard
+	if err := json.NewDecoder(rec.Body).Decode(&region); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	aggregates := region.Aggregates
+
+	if region.Name != "Nordic" || region.CountryCount != 2 {
+		t.Errorf("Expected Nordic with 2 countries, got %s with %d", region.Name, region.CountryCount)
+	}
+	if aggregates.TotalPopulation != 15379475 || aggregates.TotalArea != 835502 {
+		t.Errorf("Unexpected totals: %d inhabitants, %f km²", aggregates.TotalPopulation, aggregates.TotalArea)
+	}
+	expectedMean := math.Round((3.0*5379475+6.0*10000000)/15379475*100) / 100
+	if aggregates.MeanTemperature == nil || *aggregates.MeanTemperature != expectedMean {
+		t.Errorf("Expected a weighted mean of %.2f °C, got %v", expectedMean, aggregates.MeanTemperature)
+	}
+	if len(aggregates.Currencies) != 3 {
+		t.Errorf("Expected the currencies EUR, NOK and USD, got %v", aggregates.Currencies)
+	}
+	if len(region.Countries) != 2 || region.Countries[1].IsoCode != "SE" {
+		t.Errorf("Expected the details of Norway and Sweden, got %v", region.Countries)
+	}
+}
+
+/*
+TestDashboardNeighbours tests that the borders are resolved into summaries of the bordering countries
+*/
+func TestDashboardNeighbours(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{
+			"neighbours": map[string]interface{}{"enabled": true, "temperature": true},
+		}}, nil
+	})
+	countries := map[string]*utils.CountryResponse{
+		"NO":  {Borders: []string{"SWE", "FIN"}},
+		"SWE": {Capital: []string{"Stockholm"}, Population: 10353442, Latlng: []float64{62.0, 15.0}},
+		"FIN": {Capital: []string{"Helsinki"}, Population: 5530719},
+	}
+	countries["SWE"].Name.Common = "Sweden"
+	countries["FIN"].Name.Common = "Finland"
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		return countries[iso], nil
+	})
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features struct {
+			Neighbours []struct {
+				IsoCode     string   `json:"isoCode"`
+				Name        string   `json:"name"`
+				Capital     []string `json:"capital"`
+				Population  int      `json:"population"`
+				Temperature *float64 `json:"temperature"`
+			} `json:"neighbours"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	neighbours := body.Features.Neighbours
+
+	if len(neighbours) != 2 || neighbours[0].Name != "Sweden" || neighbours[1].Capital[0] != "Helsinki" {
+		t.Fatalf("Expected Sweden and Finland in border order, got %+v", neighbours)
+	}
+	// Finland has no coordinates, so only Sweden has a temperature
+	if neighbours[0].Temperature == nil || *neighbours[0].Temperature != 2.0 || neighbours[1].Temperature != nil {
+		t.Errorf("Expected 2 °C in Sweden and no temperature in Finland, got %v and %v", neighbours[0].Temperature, neighbours[1].Temperature)
+	}
+}
+
+// mockLatency is the simulated response time of every upstream API in the benchmark
+const mockLatency = 20 * time.Millisecond
+
+/*
+mockMultiCurrencyCountry sets predefined country data for a country with several currencies
+*/
+func mockMultiCurrencyCountry(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+	data, _ := mockGetCountryData(ctx, country, iso)
+	data.Currencies["EUR"] = struct {
+		Name   string `json:"name"`
+		Symbol string `json:"symbol"`
+	}{Name: "Euro", Symbol: "€"}
+	data.Currencies["USD"] = struct {
+		Name   string `json:"name"`
+		Symbol string `json:"symbol"`
+	}{Name: "United States dollar", Symbol: "$"}
+	return data, nil
+}
+
+/*
+withLatency wraps the upstream mocks so every call blocks for mockLatency
+*/
+func withLatency(t testing.TB) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		time.Sleep(mockLatency)
+		return mockMultiCurrencyCountry(ctx, country, iso)
+	})
+	replaceForTest(t, &clients.GetWeatherDate, func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+		time.Sleep(mockLatency)
+		return mockGetWeatherDate(ctx, lat, lon, days)
+	})
+	replaceForTest(t, &clients.GetCurrencyRates, func(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
+		time.Sleep(mockLatency)
+		return mockGetCurrencyRates(ctx, targets, base)
+	})
+}
+
+/*
+benchmarkDashboard requests a dashboard with three currencies where every upstream call takes mockLatency
+*/
+func benchmarkDashboard(b *testing.B) {
+	withLatency(b)
+
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil)
+		rec := httptest.NewRecorder()
+		DashboardHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			b.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+	}
+}
+
+/*
+BenchmarkDashboardHandler measures the dashboard handler. Weather and currencies run concurrently, so a
+request takes about two round trips.
+*/
+func BenchmarkDashboardHandler(b *testing.B) {
+	benchmarkDashboard(b)
+}
+
+/*
+BenchmarkDashboardSequentialBaseline measures the same handler with one upstream call at a time, as the
+dashboard did before, giving the latency to compare BenchmarkDashboardHandler against (five round trips)
+*/
+func BenchmarkDashboardSequentialBaseline(b *testing.B) {
+	replaceForTest(b, &config.UpstreamConcurrency, 1)
+	benchmarkDashboard(b)
+}
+
+/*
+TestDashboardUpstreamErrorStatus tests a weather upstream that answers after the deadline of the request, and
+one that fails at once, expected result: gateway timeout for the first, bad gateway for the second
+*/
+func TestDashboardUpstreamErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		weather  func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error)
+		expected int
+	}{
+		{"deadline exceeded", func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, http.StatusGatewayTimeout},
+		{"upstream failed", func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+			return nil, errors.New("service unavailable")
+		}, http.StatusBadGateway},
+	}
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetCurrencyRates, mockGetCurrencyRates)
+
+	for _, test := range tests {
+		replaceForTest(t, &clients.GetWeatherDate, test.weather)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		DashboardHandler(rec, req)
+		cancel()
+
+		if rec.Code != test.expected {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.expected, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "weather") {
+			t.Errorf("%s: expected the weather upstream to be named, got %s", test.name, rec.Body.String())
+		}
+	}
+}
+
+/*
+TestDashboardQueryOverrides tests that fields narrow the features of one request and currencies add target
+currencies, without fetching the weather of an excluded feature or changing the stored registration
+*/
+func TestDashboardQueryOverrides(t *testing.T) {
+	stored := &utils.Dashboard{
+		Id:      "mock-id",
+		Country: "Norway",
+		IsoCode: "NO",
+		Features: utils.Features{
+			"capital":          true,
+			"temperature":      true,
+			"targetCurrencies": []string{"EUR"},
+		},
+	}
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return stored, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+		t.Error("Expected no weather request for an excluded feature")
+		return mockGetWeatherDate(ctx, lat, lon, days)
+	})
+	var requested []string
+	replaceForTest(t, &clients.GetCurrencyRates, func(ctx context.Context, targets []string, base string) (*utils.CurrencyAPIResult, error) {
+		requested = targets
+		return mockGetCurrencyRates(ctx, targets, base)
+	})
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id?fields=capital,targetCurrencies&currencies=usd", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Features map[string]json.RawMessage `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if _, exists := body.Features["temperature"]; exists {
+		t.Error("Expected temperature to be left out")
+	}
+	var groups []utils.GroupedCurrencyResponse
+	if err := json.Unmarshal(body.Features["targetCurrencies"], &groups); err != nil {
+		t.Fatalf("Error decoding target currencies: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Rates) != 2 {
+		t.Errorf("Expected the rates of EUR and USD, got %+v", groups)
+	}
+	if strings.Join(requested, ",") != "EUR,USD" {
+		t.Errorf("Expected EUR and USD to be requested, got %v", requested)
+	}
+
+	if !stored.Features.Bool("temperature") || len(stored.Features.Strings("targetCurrencies")) != 1 {
+		t.Errorf("Expected the stored registration to be unchanged, got %+v", stored.Features)
+	}
+}
+
+/*
+TestDashboardUnknownField tests a request for a field that is not a feature, and for currencies the fields leave
+out, expected result: bad request
+*/
+func TestDashboardUnknownField(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+
+	// An unknown field, and currencies added to the target currencies that the fields leave out
+	for _, query := range []string{"fields=capital,volcanoes", "fields=capital&currencies=USD"} {
+		req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id?"+query, nil)
+		rec := httptest.NewRecorder()
+
+		DashboardHandler(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+/*
+TestDashboardUnitsAndLocale tests an imperial registration shown with German display strings and names
+*/
+func TestDashboardUnitsAndLocale(t *testing.T) {
+	fixture, err := os.ReadFile("../stub-data/restcountries.json")
+	if err != nil {
+		t.Fatalf("Error reading fixture: %v", err)
+	}
+	var countries []utils.CountryResponse
+	if err := json.Unmarshal(fixture, &countries); err != nil {
+		t.Fatalf("Error decoding fixture: %v", err)
+	}
+
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{
+			Id:       id,
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: utils.Features{"area": true, "temperature": true, "names": true},
+			Display:  utils.DisplaySetting{Units: utils.UnitsImperial},
+		}, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, func(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+		return &countries[0], nil
+	})
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id?locale=de-DE&lang=deu", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var dashboard struct {
+		Country  string `json:"country"`
+		Units    string `json:"units"`
+		Features struct {
+			Area        float64           `json:"area"`
+			Temperature float64           `json:"temperature"`
+			Names       map[string]string `json:"names"`
+		} `json:"features"`
+		Formatted map[string]string `json:"formatted"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&dashboard); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+
+	if dashboard.Units != utils.UnitsImperial {
+		t.Errorf("Expected imperial units, got %s", dashboard.Units)
+	}
+	if dashboard.Features.Area != 125020.65 || dashboard.Features.Temperature != 37.4 {
+		t.Errorf("Expected 125020.65 mi² and 37.4 °F, got %v and %v", dashboard.Features.Area, dashboard.Features.Temperature)
+	}
+	if dashboard.Formatted["area"] != "125.020,65 mi²" || dashboard.Formatted["temperature"] != "37,4 °F" {
+		t.Errorf("Unexpected display strings: %v", dashboard.Formatted)
+	}
+	if dashboard.Country != "Norwegen" || dashboard.Features.Names["official"] != "Königreich Norwegen" {
+		t.Errorf("Expected German names, got %s and %v", dashboard.Country, dashboard.Features.Names)
+	}
+}
+
+/*
+TestDashboardUnsupportedUnits tests a request for an unknown unit system, expected result: bad request
+*/
+func TestDashboardUnsupportedUnits(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, mockGetOneRegistration)
+
+	req := httptest.NewRequest("GET", "/dashboard/v1/dashboards/mock-id?units=nautical", nil)
+	rec := httptest.NewRecorder()
+
+	DashboardHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/services"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+/*
+GeoJSONHandler routes HTTP requests to the appropriate GeoJSON method handler
+*/
+func GeoJSONHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handleGeoJSONGetRequest(w, r)
+	default:
+		http.Error(w, "REST method '"+r.Method+"' not supported. "+
+			"Currently only '"+http.MethodGet+"' is supported.", http.StatusNotImplemented)
+		return
+	}
+}
+
+/*
+handleGeoJSONGetRequest Returns every registration with the coordinates feature as a GeoJSON FeatureCollection,
+optionally filtered as ?region=Europe&features=population,temperature
+*/
+func handleGeoJSONGetRequest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	features, err := services.ParseFeatureNames(query.Get("features"))
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	region := strings.TrimSpace(query.Get("region"))
+
+	regs, err := database.GetAllRegistrations()
+	if err != nil {
+		log.Println("Error retrieving registrations: " + err.Error())
+		http.Error(w, "There was an error getting the registrations", http.StatusInternalServerError)
+		return
+	}
+
+	// All upstream calls share the deadline of this request
+	ctx, cancel := context.WithTimeout(r.Context(), config.DASHBOARD_TIMEOUT)
+	defer cancel()
+
+	collection, err := services.BuildGeoJSON(ctx, regs, region, features)
+	if err != nil {
+		log.Println("Error building GeoJSON: " + err.Error())
+		if ctx.Err() != nil {
+			http.Error(w, "Timed out building the GeoJSON", upstreamErrorStatus(ctx))
+			return
+		}
+		http.Error(w, "There was an error building the GeoJSON", http.StatusInternalServerError)
+		return
+	}
+
+	// Send the final response
+	w.Header().Set("Content-Type", "application/geo+json")
+	if err := json.NewEncoder(w).Encode(collection); err != nil {
+		log.Println("Error encoding response: " + err.Error())
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"assignment-2/clients"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+mockGeoJSONRegistrations sets predefined registrations: two countries with coordinates, one without,
+and a comparison
+*/
+func mockGeoJSONRegistrations() ([]utils.Dashboard, error) {
+	return []utils.Dashboard{
+		{Id: "norway", Country: "Norway", IsoCode: "NO", Features: utils.Features{"coordinates": true, "population": true, "temperature": true}},
+		{Id: "chile", Country: "Chile", IsoCode: "CL", Features: utils.Features{"coordinates": true, "population": true}},
+		{Id: "sweden", Country: "Sweden", IsoCode: "SE", Features: utils.Features{"population": true}},
+		{Id: "nordics", Type: utils.RegistrationComparison, Features: utils.Features{"coordinates": true},
+			Countries: []utils.CountryRef{{IsoCode: "NO"}, {IsoCode: "SE"}}},
+	}, nil
+}
+
+/*
+mockGeoJSONCountry sets predefined country data by ISO code
+*/
+func mockGeoJSONCountry(ctx context.Context, country, iso string) (*utils.CountryResponse, error) {
+	if iso == "CL" {
+		data := &utils.CountryResponse{Population: 19116209, Latlng: []float64{-30, -71}, Region: "Americas"}
+		data.Name.Common = "Chile"
+		return data, nil
+	}
+	data := &utils.CountryResponse{Population: 5379475, Latlng: []float64{62, 10}, Region: "Europe", Subregion: "Northern Europe"}
+	data.Name.Common = "Norway"
+	return data, nil
+}
+
+/*
+getGeoJSON sends a GeoJSON request and decodes the response
+*/
+func getGeoJSON(t *testing.T, query string) utils.GeoJSONFeatureCollection {
+	req := httptest.NewRequest("GET", "/dashboard/v1/geojson/"+query, nil)
+	rec := httptest.NewRecorder()
+
+	GeoJSONHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/geo+json" {
+		t.Errorf("Expected GeoJSON, got %s", contentType)
+	}
+
+	var collection utils.GeoJSONFeatureCollection
+	if err := json.NewDecoder(rec.Body).Decode(&collection); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	return collection
+}
+
+/*
+TestGeoJSONHandler tests that every country registration with coordinates is a Point with its values as properties
+*/
+func TestGeoJSONHandler(t *testing.T) {
+	replaceForTest(t, &database.GetAllRegistrations, mockGeoJSONRegistrations)
+	replaceForTest(t, &clients.GetCountryData, mockGeoJSONCountry)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	collection := getGeoJSON(t, "")
+
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 {
+		t.Fatalf("Expected a collection of 2 features, got %+v", collection)
+	}
+	norway := collection.Features[0]
+	if norway.Id != "norway" || norway.Geometry.Type != "Point" {
+		t.Errorf("Expected the Point of norway first, got %+v", norway)
+	}
+	if coordinates := norway.Geometry.Coordinates; len(coordinates) != 2 || coordinates[0] != 10 || coordinates[1] != 62 {
+		t.Errorf("Expected the coordinates [10, 62], got %v", coordinates)
+	}
+	if norway.Properties["population"] != 5379475.0 || norway.Properties["temperature"] != 3.0 {
+		t.Errorf("Unexpected properties: %v", norway.Properties)
+	}
+	if _, exists := norway.Properties["coordinates"]; exists {
+		t.Error("Expected the coordinates only in the geometry")
+	}
+}
+
+/*
+TestGeoJSONHandlerFilters tests that the region and features filters keep only the matching registrations
+*/
+func TestGeoJSONHandlerFilters(t *testing.T) {
+	replaceForTest(t, &database.GetAllRegistrations, mockGeoJSONRegistrations)
+	replaceForTest(t, &clients.GetCountryData, mockGeoJSONCountry)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	collection := getGeoJSON(t, "?region=americas")
+	if len(collection.Features) != 1 || collection.Features[0].Id != "chile" {
+		t.Errorf("Expected only chile in the Americas, got %+v", collection.Features)
+	}
+
+	collection = getGeoJSON(t, "?features=temperature")
+	if len(collection.Features) != 1 || collection.Features[0].Id != "norway" {
+		t.Fatalf("Expected only norway with the temperature, got %+v", collection.Features)
+	}
+	if _, exists := collection.Features[0].Properties["population"]; exists {
+		t.Error("Expected only the filtered features as properties")
+	}
+}
+
+/*
+TestGeoJSONHandlerUnknownFeature tests a filter on a feature that does not exist, expected result: bad request
+*/
+func TestGeoJSONHandlerUnknownFeature(t *testing.T) {
+	req := httptest.NewRequest("GET", "/dashboard/v1/geojson/?features=volcanoes", nil)
+	rec := httptest.NewRecorder()
+
+	GeoJSONHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+/*
+TestGeoJSONHandlerTimeout tests a weather upstream that answers after the deadline of the request, expected
+result: gateway timeout, without blaming the country upstream
+*/
+func TestGeoJSONHandlerTimeout(t *testing.T) {
+	replaceForTest(t, &database.GetAllRegistrations, mockGeoJSONRegistrations)
+	replaceForTest(t, &clients.GetCountryData, mockGeoJSONCountry)
+	replaceForTest(t, &clients.GetWeatherDate, func(ctx context.Context, lat float64, lon float64, days int) (*utils.WeatherForecast, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/dashboard/v1/geojson/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	GeoJSONHandler(rec, req)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("Expected status 504, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "country") {
+		t.Errorf("Expected no upstream to be named, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/services"
+	"assignment-2/utils"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+HistoryHandler routes HTTP requests to the appropriate rate history method handler
+*/
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handleHistoryGetRequest(w, r)
+	default:
+		http.Error(w, "REST method '"+r.Method+"' not supported. "+
+			"Currently only '"+http.MethodGet+"' is supported.", http.StatusNotImplemented)
+		return
+	}
+}
+
+/*
+handleHistoryGetRequest Returns every stored rate of a base/target pair, one for every fetch, given as
+?base=NOK&target=EUR&days=30. The last 30 days are returned if days is left out.
+*/
+func handleHistoryGetRequest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	codes, err := services.ParseCurrencyCodes(query.Get("base") + "," + query.Get("target"))
+	if err != nil || len(codes) != 2 {
+		http.Error(w, "Query parameters 'base' and 'target' must both be three-letter currency codes", http.StatusBadRequest)
+		return
+	}
+
+	days := 30
+	if rawDays := query.Get("days"); rawDays != "" {
+		days, err = strconv.Atoi(rawDays)
+		if err != nil || days < 1 || days > config.MAX_HISTORY_DAYS {
+			http.Error(w, "Query parameter 'days' must be a number from 1 to "+strconv.Itoa(config.MAX_HISTORY_DAYS), http.StatusBadRequest)
+			return
+		}
+	}
+
+	points, err := database.GetRateHistory(r.Context(), codes[0], codes[1], time.Now().UTC().AddDate(0, 0, -days))
+	if err != nil {
+		log.Println("Error getting rate history: " + err.Error())
+		http.Error(w, "There was an error getting the rate history", http.StatusInternalServerError)
+		return
+	}
+
+	response := utils.RateHistoryResponse{Base: codes[0], Target: codes[1], Days: days, Points: points}
+
+	// Send the final response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println("Error encoding response: " + err.Error())
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
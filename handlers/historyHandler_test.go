@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/*
+TestHistoryHandler tests that the rate history of a pair is returned for the requested days
+*/
+func TestHistoryHandler(t *testing.T) {
+	var requestedSince time.Time
+	replaceForTest(t, &database.GetRateHistory, func(ctx context.Context, base string, target string, since time.Time) ([]utils.RatePoint, error) {
+		requestedSince = since
+		return []utils.RatePoint{{Date: "2025-04-10", Rate: 0.085, Source: "campus"}}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, config.START_URL+"/history/?base=nok&target=eur&days=7", nil)
+	rec := httptest.NewRecorder()
+
+	HistoryHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if days := time.Since(requestedSince).Hours() / 24; days < 6.9 || days > 7.1 {
+		t.Errorf("Expected the history of the last 7 days, got %.1f days", days)
+	}
+
+	var response utils.RateHistoryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if response.Base != "NOK" || response.Target != "EUR" || len(response.Points) != 1 {
+		t.Errorf("Unexpected history: %+v", response)
+	}
+}
+
+/*
+TestHistoryHandlerTooManyDays tests that a period beyond the longest supported is rejected, expected result: bad request
+*/
+func TestHistoryHandlerTooManyDays(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, config.START_URL+"/history/?base=NOK&target=EUR&days=1000", nil)
+	rec := httptest.NewRecorder()
+
+	HistoryHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+}
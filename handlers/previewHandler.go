@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"assignment-2/config"
+	"assignment-2/services"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+)
+
+/*
+PreviewHandler routes HTTP requests to the appropriate preview method handler
+*/
+func PreviewHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		handlePreviewPostRequest(w, r)
+	default:
+		http.Error(w, "REST method '"+r.Method+"' not supported. "+
+			"Currently only '"+http.MethodPost+"' is supported.", http.StatusNotImplemented)
+		return
+	}
+}
+
+/*
+handlePreviewPostRequest Populates the dashboard of a registration payload without storing it. The payload is
+validated as a new registration, but no webhooks are triggered.
+*/
+func handlePreviewPostRequest(w http.ResponseWriter, r *http.Request) {
+
+	// Choose the output format before any work is done
+	format, err := negotiateFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), formatErrorStatus(err))
+		return
+	}
+
+	// Read the body
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Println("Error reading request body: " + err.Error())
+		http.Error(w, "Reading payload failed.", http.StatusInternalServerError)
+		return
+	}
+
+	defer r.Body.Close()
+
+	if len(content) == 0 {
+		http.Error(w, "Your payload appears to be empty.", http.StatusBadRequest)
+		return
+	}
+
+	// Check the features against the feature registry, then decode and check the remaining settings
+	dashboard, err := decodeRegistration(content)
+	if err != nil {
+		http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The preview is built like a stored registration, only without an id
+	reg := &utils.Dashboard{
+		Country:         dashboard.Country,
+		IsoCode:         dashboard.IsoCode,
+		Features:        dashboard.Features,
+		WeatherLocation: dashboard.WeatherLocation,
+		Points:          dashboard.Points,
+		Formulas:        dashboard.Formulas,
+		Currency:        dashboard.Currency,
+		Display:         dashboard.Display,
+		Type:            dashboard.Type,
+		Countries:       dashboard.Countries,
+		Region:          dashboard.Region,
+	}
+
+	// All upstream calls share the deadline of this request
+	ctx, cancel := context.WithTimeout(r.Context(), config.DASHBOARD_TIMEOUT)
+	defer cancel()
+
+	response, err := buildDashboardResponse(ctx, r, reg)
+	if err != nil {
+		log.Println("Error building dashboard preview: " + err.Error())
+		var upstreamErr *services.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			http.Error(w, "Failed to fetch "+string(upstreamErr.Upstream)+" data", upstreamErrorStatus(ctx))
+			return
+		}
+		http.Error(w, "There was an error building the dashboard preview", http.StatusInternalServerError)
+		return
+	}
+
+	// Send the final response in the negotiated format
+	if err := writeDashboard(w, format, dashboardTitle(response), response); err != nil {
+		log.Println("Error encoding response: " + err.Error())
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/database"
+	"assignment-2/utils"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+recordingTrigger records the webhook events it is asked to trigger
+*/
+type recordingTrigger struct {
+	events []string
+}
+
+func (r *recordingTrigger) TriggerWebhooks(event string, country string) {
+	r.events = append(r.events, event)
+}
+
+/*
+TestPreviewHandler tests that a registration payload is populated without being stored or triggering webhooks
+*/
+func TestPreviewHandler(t *testing.T) {
+	trigger := &recordingTrigger{}
+	SetHandlerWebhookTrigger(trigger)
+	defer SetHandlerWebhookTrigger(nil)
+
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		t.Errorf("Expected no registration to be read, got a lookup of %s", id)
+		return nil, nil
+	})
+	replaceForTest(t, &clients.GetCountryData, mockGetCountryData)
+	replaceForTest(t, &clients.GetWeatherDate, mockGetWeatherDate)
+
+	postData := []byte(`{"country": "Norway", "isoCode": "NO", "features": {"population": true, "temperature": true}}`)
+	req := httptest.NewRequest(http.MethodPost, config.START_URL+"/preview/", bytes.NewBuffer(postData))
+	rec := httptest.NewRecorder()
+
+	PreviewHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var dashboard utils.PopulatedDashboard
+	if err := json.NewDecoder(rec.Body).Decode(&dashboard); err != nil {
+		t.Fatalf("Error decoding JSON response: %v", err)
+	}
+	if dashboard.Features["population"] != 5379475.0 || dashboard.Features["temperature"] != 3.0 {
+		t.Errorf("Unexpected preview features: %v", dashboard.Features)
+	}
+	if len(trigger.events) != 0 {
+		t.Errorf("Expected no webhooks, got %v", trigger.events)
+	}
+}
+
+/*
+TestPreviewHandlerUnknownFeature tests that a preview is validated like a registration, expected result: bad request
+*/
+func TestPreviewHandlerUnknownFeature(t *testing.T) {
+	postData := []byte(`{"country": "Norway", "isoCode": "NO", "features": {"volcanoes": true}}`)
+	req := httptest.NewRequest(http.MethodPost, config.START_URL+"/preview/", bytes.NewBuffer(postData))
+	rec := httptest.NewRecorder()
+
+	PreviewHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+}
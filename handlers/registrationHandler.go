@@ -3,6 +3,7 @@ package handlers
 import (
 	"assignment-2/config"
 	"assignment-2/database"
+	"assignment-2/services"
 	"assignment-2/utils"
 	"encoding/json"
 	"fmt"
@@ -140,12 +141,10 @@ func handleRegPostRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dashboard := utils.DashboardPost{}
-	// Decode JSON into the dashboard struct
-	err = json.Unmarshal(content, &dashboard)
+	// Check the features against the feature registry, then decode and check the remaining settings
+	dashboard, err := decodeRegistration(content)
 	if err != nil {
-		log.Println("Error unmarshalling payload: " + err.Error())
-		http.Error(w, "There was an error unmarshalling payload", http.StatusInternalServerError)
+		http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	dashboard.LastChange = time.Now().Local().String()
@@ -209,12 +208,10 @@ func handleRegPutRequest(w http.ResponseWriter, r *http.Request, id string) {
 		return
 	}
 
-	dashboard := utils.DashboardPost{}
-	// Decode JSON into the dashboard struct
-	err = json.Unmarshal(content, &dashboard)
+	// Check the features against the feature registry, then decode and check the remaining settings
+	dashboard, err := decodeRegistration(content)
 	if err != nil {
-		log.Println("Error unmarshalling payload: " + err.Error())
-		http.Error(w, "There was an error unmarshalling payload", http.StatusInternalServerError)
+		http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -306,8 +303,7 @@ func handleRegPatchRequest(w http.ResponseWriter, r *http.Request, id string) {
 	var patchData map[string]interface{}
 	err = json.Unmarshal(content, &patchData)
 	if err != nil {
-		log.Println("Error unmarshalling payload: " + err.Error())
-		http.Error(w, "There was an error unmarshalling payload", http.StatusInternalServerError)
+		http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -353,8 +349,12 @@ func handleRegPatchRequest(w http.ResponseWriter, r *http.Request, id string) {
 		mergedIsoCode = origVal
 	}
 
-	// If both are empty, an error is returned.
-	if mergedCountry == "" && mergedIsoCode == "" {
+	// If both are empty, an error is returned, except for a comparison or region, which define their countries otherwise
+	mergedType, _ := originalData["type"].(string)
+	if patchVal, ok := patchData["type"].(string); ok {
+		mergedType = patchVal
+	}
+	if mergedCountry == "" && mergedIsoCode == "" && mergedType != utils.RegistrationComparison && mergedType != utils.RegistrationRegion {
 		http.Error(w, "Both country code and isoCode cannot be empty", http.StatusBadRequest)
 		return
 	}
@@ -371,19 +371,22 @@ func handleRegPatchRequest(w http.ResponseWriter, r *http.Request, id string) {
 			http.Error(w, "Invalid format for features", http.StatusBadRequest)
 			return
 		}
-		// check if original data has a features field
-		if origVal, exists := originalData["features"]; exists {
-			if origFeatures, ok := origVal.(map[string]interface{}); ok {
-				for key, value := range patchFeatures {
-					origFeatures[key] = value
-				}
-				originalData["features"] = origFeatures
-			} else {
-				originalData["features"] = patchFeatures
-			}
-		} else {
-			originalData["features"] = patchFeatures
+		// Check the patched features against the feature registry
+		if err := services.ValidateFeatures(patchFeatures); err != nil {
+			http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
+			return
 		}
+		origFeatures, _ := originalData["features"].(map[string]interface{})
+		originalData["features"] = mergeFeatures(origFeatures, patchFeatures)
+	}
+
+	// Any other setting in the patch replaces the original setting
+	for key, value := range patchData {
+		switch key {
+		case "id", "country", "isoCode", "features", "lastChange":
+			continue
+		}
+		originalData[key] = value
 	}
 
 	// Update timestamp
@@ -396,12 +399,17 @@ func handleRegPatchRequest(w http.ResponseWriter, r *http.Request, id string) {
 		return
 	}
 
+	// A patched setting of the wrong type fails to decode
 	var updatedData utils.DashboardPost
 	err = json.Unmarshal(originalDataJson, &updatedData)
-	//Patch with request body
 	if err != nil {
-		log.Println("Error unmarshalling updated data: " + err.Error())
-		http.Error(w, "Error processing updated data", http.StatusInternalServerError)
+		http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Check the settings of the patched registration
+	if err := validateRegistration(updatedData); err != nil {
+		http.Error(w, "Invalid registration: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -419,6 +427,87 @@ func handleRegPatchRequest(w http.ResponseWriter, r *http.Request, id string) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+/*
+mergeFeatures Merges patched features into the original features. An options object is merged property by
+property, so patching {"dailyForecast": {"days": 3}} keeps the forecast enabled; any other value is replaced.
+*/
+func mergeFeatures(original map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(original)+len(patch))
+	for name, value := range original {
+		merged[name] = value
+	}
+	for name, value := range patch {
+		patchOptions, patchIsObject := value.(map[string]interface{})
+		origOptions, origIsObject := merged[name].(map[string]interface{})
+		if !patchIsObject || !origIsObject {
+			merged[name] = value
+			continue
+		}
+		options := make(map[string]interface{}, len(origOptions)+len(patchOptions))
+		for property, option := range origOptions {
+			options[property] = option
+		}
+		for property, option := range patchOptions {
+			options[property] = option
+		}
+		merged[name] = options
+	}
+	return merged
+}
+
+/*
+decodeRegistration Decodes a registration payload. The features are checked against the feature registry before
+the payload is decoded, so a feature value of the wrong type is reported as such, and the remaining settings after.
+*/
+func decodeRegistration(content []byte) (utils.DashboardPost, error) {
+	dashboard := utils.DashboardPost{}
+	if err := validateFeaturesPayload(content); err != nil {
+		return dashboard, err
+	}
+	if err := json.Unmarshal(content, &dashboard); err != nil {
+		return dashboard, err
+	}
+	return dashboard, validateRegistration(dashboard)
+}
+
+/*
+validateRegistration Checks the settings of a registration outside its features
+*/
+func validateRegistration(dashboard utils.DashboardPost) error {
+	if err := services.ValidateRegistrationType(dashboard); err != nil {
+		return err
+	}
+	if err := services.ValidateWeatherLocation(dashboard.WeatherLocation); err != nil {
+		return fmt.Errorf("invalid weatherLocation: %w", err)
+	}
+	if err := services.ValidatePoints(dashboard.Points); err != nil {
+		return fmt.Errorf("invalid points: %w", err)
+	}
+	if err := services.ValidateCurrencySetting(dashboard.Currency); err != nil {
+		return fmt.Errorf("invalid currency: %w", err)
+	}
+	if err := services.ValidateDisplaySetting(dashboard.Display); err != nil {
+		return fmt.Errorf("invalid display: %w", err)
+	}
+	if err := services.ValidateFormulas(dashboard.Formulas, dashboard.Features); err != nil {
+		return fmt.Errorf("invalid formulas: %w", err)
+	}
+	return nil
+}
+
+/*
+validateFeaturesPayload Checks the features object of a registration payload against the feature registry
+*/
+func validateFeaturesPayload(content []byte) error {
+	var payload struct {
+		Features map[string]interface{} `json:"features"`
+	}
+	if err := json.Unmarshal(content, &payload); err != nil {
+		return err
+	}
+	return services.ValidateFeatures(payload.Features)
+}
+
 /*
 handleRegHeadRequest Provides only the headers for a registration or all registrations in the dashboard database
 */
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"assignment-2/config"
+	"assignment-2/database"
 	"assignment-2/utils"
 	"bytes"
 	"encoding/json"
@@ -24,13 +25,13 @@ func TestPostRegistration(t *testing.T) {
 		Country: "Norway",
 		IsoCode: "NO",
 		Features: utils.Features{
-			Temperature:      true,
-			Precipitation:    false,
-			Capital:          true,
-			Coordinates:      true,
-			Population:       false,
-			Area:             false,
-			TargetCurrencies: []string{"EUR", "SEK", "DEK"},
+			"temperature":      true,
+			"precipitation":    false,
+			"capital":          true,
+			"coordinates":      true,
+			"population":       false,
+			"area":             false,
+			"targetCurrencies": []string{"EUR", "SEK", "DEK"},
 		},
 		LastChange: time.Now().Local().String(),
 	}
@@ -106,13 +107,13 @@ func TestGetOne(t *testing.T) {
 		Country: "Norway",
 		IsoCode: "NO",
 		Features: utils.Features{
-			Temperature:      true,
-			Precipitation:    false,
-			Capital:          true,
-			Coordinates:      true,
-			Population:       false,
-			Area:             false,
-			TargetCurrencies: []string{"EUR", "SEK", "DEK"},
+			"temperature":      true,
+			"precipitation":    false,
+			"capital":          true,
+			"coordinates":      true,
+			"population":       false,
+			"area":             false,
+			"targetCurrencies": []string{"EUR", "SEK", "DEK"},
 		},
 	}
 
@@ -125,8 +126,8 @@ func TestGetOne(t *testing.T) {
 		t.Errorf("Expected Country %s, got Country %s", expected.Country, gotten.Country)
 	}
 
-	if expected.Features.Temperature != gotten.Features.Temperature {
-		t.Errorf("Expected value %t, got value %t", expected.Features.Temperature, gotten.Features.Temperature)
+	if expected.Features.Bool("temperature") != gotten.Features.Bool("temperature") {
+		t.Errorf("Expected value %t, got value %t", expected.Features.Bool("temperature"), gotten.Features.Bool("temperature"))
 	}
 }
 
@@ -174,3 +175,178 @@ func TestDeleteRegistration(t *testing.T) {
 		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, resp.StatusCode)
 	}
 }
+
+/*
+TestPostRegistrationUnknownFeature tests that a registration with a feature missing from the feature
+registry is rejected, expected result: bad request
+*/
+func TestPostRegistrationUnknownFeature(t *testing.T) {
+	// Define a test post body with an unknown feature
+	postData := []byte(`{"country": "Norway", "isoCode": "NO", "features": {"capital": true, "volcanoes": true}}`)
+
+	// Create the request
+	req := httptest.NewRequest(http.MethodPost, config.START_URL+"/registrations/", bytes.NewBuffer(postData))
+	w := httptest.NewRecorder()
+
+	// Send request to the handler
+	RegistrationHandler(w, req)
+
+	// Capture result
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+/*
+TestPostRegistrationInvalidPoint tests that a registration with a named point outside the valid
+coordinates is rejected, expected result: bad request
+*/
+func TestPostRegistrationInvalidPoint(t *testing.T) {
+	// Define a test post body with a latitude beyond the pole
+	postData := []byte(`{"country": "Norway", "isoCode": "NO", "features": {"temperature": true},
+		"points": [{"label": "North of the pole", "latitude": 95.0, "longitude": 10.0}]}`)
+
+	// Create the request
+	req := httptest.NewRequest(http.MethodPost, config.START_URL+"/registrations/", bytes.NewBuffer(postData))
+	w := httptest.NewRecorder()
+
+	// Send request to the handler
+	RegistrationHandler(w, req)
+
+	// Capture result
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+/*
+TestPostRegistrationUnsafeFormula tests that a registration with a formula that is more than arithmetic
+is rejected, expected result: bad request
+*/
+func TestPostRegistrationUnsafeFormula(t *testing.T) {
+	// Define a test post body with a function call in a formula
+	postData := []byte(`{"country": "Norway", "isoCode": "NO", "features": {"population": true},
+		"formulas": [{"name": "exit", "expression": "os.Exit(1) + population"}]}`)
+
+	// Create the request
+	req := httptest.NewRequest(http.MethodPost, config.START_URL+"/registrations/", bytes.NewBuffer(postData))
+	w := httptest.NewRecorder()
+
+	// Send request to the handler
+	RegistrationHandler(w, req)
+
+	// Capture result
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+/*
+TestPostRegistrationUnresolvableFormula tests that a registration with a formula variable that can never have
+a value is rejected, expected result: bad request
+*/
+func TestPostRegistrationUnresolvableFormula(t *testing.T) {
+	for _, expression := range []string{"rates * 100", "population.foo", "capital.anything + 1", "0x10 * population"} {
+		postData := []byte(`{"country": "Norway", "isoCode": "NO", "features": {"population": true, "targetCurrencies": ["EUR"]},
+			"formulas": [{"name": "unresolvable", "expression": "` + expression + `"}]}`)
+
+		req := httptest.NewRequest(http.MethodPost, config.START_URL+"/registrations/", bytes.NewBuffer(postData))
+		w := httptest.NewRecorder()
+
+		RegistrationHandler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", expression, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+/*
+TestPostRegistrationComparisonTooFewCountries tests that a comparison of a single country is rejected,
+expected result: bad request
+*/
+func TestPostRegistrationComparisonTooFewCountries(t *testing.T) {
+	// Define a test post body with a comparison of one country
+	postData := []byte(`{"type": "comparison", "countries": [{"isoCode": "NO"}], "features": {"population": true}}`)
+
+	// Create the request
+	req := httptest.NewRequest(http.MethodPost, config.START_URL+"/registrations/", bytes.NewBuffer(postData))
+	w := httptest.NewRecorder()
+
+	// Send request to the handler
+	RegistrationHandler(w, req)
+
+	// Capture result
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+/*
+TestRegistrationWrongTypedValues tests that POST and PUT reject feature values and settings of the wrong type
+as invalid registrations, expected result: bad request
+*/
+func TestRegistrationWrongTypedValues(t *testing.T) {
+	payloads := map[string]string{
+		"boolean feature":     `{"country": "Norway", "features": {"temperature": "yes"}}`,
+		"list feature":        `{"country": "Norway", "features": {"targetCurrencies": "EUR"}}`,
+		"options property":    `{"country": "Norway", "features": {"dailyForecast": {"enabled": true, "days": "3"}}}`,
+		"features not object": `{"country": "Norway", "features": ["temperature"]}`,
+		"setting":             `{"country": 47, "features": {"temperature": true}}`,
+	}
+
+	for name, payload := range payloads {
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			path := config.START_URL + "/registrations/"
+			if method == http.MethodPut {
+				path += "mock-id"
+			}
+			req := httptest.NewRequest(method, path, bytes.NewBufferString(payload))
+			w := httptest.NewRecorder()
+
+			RegistrationHandler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s %s: expected status code %d, got %d", method, name, http.StatusBadRequest, w.Code)
+			}
+		}
+	}
+}
+
+/*
+TestPatchRegistrationMergesFeatureOptions tests that patching one property of an options feature keeps its other
+properties and the other features, expected result: no content
+*/
+func TestPatchRegistrationMergesFeatureOptions(t *testing.T) {
+	replaceForTest(t, &database.GetOneRegistration, func(id string) (*utils.Dashboard, error) {
+		return &utils.Dashboard{Id: id, Country: "Norway", IsoCode: "NO", Features: utils.Features{
+			"capital":       true,
+			"dailyForecast": map[string]interface{}{"enabled": true, "days": 7},
+		}}, nil
+	})
+	var stored utils.DashboardPost
+	replaceForTest(t, &database.UpdateRegistration, func(id string, dash utils.DashboardPost) error {
+		stored = dash
+		return nil
+	})
+
+	patchData := []byte(`{"features": {"dailyForecast": {"days": 3}}}`)
+	req := httptest.NewRequest(http.MethodPatch, config.START_URL+"/registrations/mock-id", bytes.NewBuffer(patchData))
+	w := httptest.NewRecorder()
+
+	RegistrationHandler(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	if !stored.Features.OptionBool("dailyForecast", "enabled") || stored.Features.OptionInt("dailyForecast", "days") != 3 {
+		t.Errorf("Expected the forecast to stay enabled with 3 days, got %v", stored.Features["dailyForecast"])
+	}
+	if !stored.Features.Bool("capital") {
+		t.Errorf("Expected the capital to be kept, got %v", stored.Features)
+	}
+}
@@ -35,6 +35,11 @@ func main() {
 		log.Println("Successfully purged expired cache entries at startup")
 	}
 
+	// Purge exchange rates older than the retention period at startup
+	if err := database.PurgeRateHistory(database.Ctx); err != nil {
+		log.Printf("Error purging rate history at startup: %v\n", err)
+	}
+
 	// STARTING background routine for purging expired cache - Checks every hour
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
@@ -48,6 +53,9 @@ func main() {
 			} else {
 				log.Println("Expired cache entries purged successfully.")
 			}
+			if err := database.PurgeRateHistory(database.Ctx); err != nil {
+				log.Printf("Error purging rate history: %v\n", err)
+			}
 		}
 	}()
 
@@ -61,6 +69,16 @@ func main() {
 	router.HandleFunc(config.START_URL+"/dashboards", handlers.DashboardHandler)
 	router.HandleFunc(config.START_URL+"/notifications/", handlers.NotificationHandler)
 	router.HandleFunc(config.START_URL+"/notifications", handlers.NotificationHandler)
+	router.HandleFunc(config.START_URL+"/preview/", handlers.PreviewHandler)
+	router.HandleFunc(config.START_URL+"/preview", handlers.PreviewHandler)
+	router.HandleFunc(config.START_URL+"/charts/", handlers.ChartHandler)
+	router.HandleFunc(config.START_URL+"/charts", handlers.ChartHandler)
+	router.HandleFunc(config.START_URL+"/geojson/", handlers.GeoJSONHandler)
+	router.HandleFunc(config.START_URL+"/geojson", handlers.GeoJSONHandler)
+	router.HandleFunc(config.START_URL+"/convert/", handlers.ConvertHandler)
+	router.HandleFunc(config.START_URL+"/convert", handlers.ConvertHandler)
+	router.HandleFunc(config.START_URL+"/history/", handlers.HistoryHandler)
+	router.HandleFunc(config.START_URL+"/history", handlers.HistoryHandler)
 	router.HandleFunc(config.START_URL+"/status/", handlers.StatusHandler)
 	router.HandleFunc(config.START_URL+"/status", handlers.StatusHandler)
 
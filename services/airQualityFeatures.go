@@ -0,0 +1,67 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/utils"
+	"context"
+)
+
+/*
+fetchAirQuality Gets the current air quality at the weather location of the registration
+*/
+func fetchAirQuality(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	location, err := resolveWeatherLocation(reg.WeatherLocation, data.Country)
+	if err != nil {
+		return err
+	}
+	airQuality, err := clients.GetAirQuality(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return err
+	}
+	data.AirQuality = airQuality
+	return nil
+}
+
+/*
+airQualityLevel Names the band of the European Air Quality Index a value falls in, or nil without a value
+*/
+func airQualityLevel(index *float64) interface{} {
+	if index == nil {
+		return nil
+	}
+	switch {
+	case *index < 20:
+		return "good"
+	case *index < 40:
+		return "fair"
+	case *index < 60:
+		return "moderate"
+	case *index < 80:
+		return "poor"
+	case *index < 100:
+		return "very poor"
+	default:
+		return "extremely poor"
+	}
+}
+
+/*
+init Registers the air quality feature
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "airQuality",
+		dependencies: []Upstream{UpstreamAirQuality},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Current PM2.5, PM10 and ozone in μg/m³, and the European Air Quality Index"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return map[string]interface{}{
+				"time":        data.AirQuality.Time,
+				"pm2_5":       data.AirQuality.PM25,
+				"pm10":        data.AirQuality.PM10,
+				"ozone":       data.AirQuality.Ozone,
+				"europeanAqi": data.AirQuality.EuropeanAQI,
+				"level":       airQualityLevel(data.AirQuality.EuropeanAQI),
+			}, nil
+		},
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"errors"
+)
+
+/*
+init Registers the built-in dashboard features
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "capital",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Capital city of the country"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return data.Country.Capital, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "coordinates",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Geographic centre of the country"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			if len(data.Country.Latlng) < 2 {
+				return nil, errors.New("no coordinates found for country")
+			}
+			return map[string]float64{
+				"latitude":  data.Country.Latlng[0],
+				"longitude": data.Country.Latlng[1],
+			}, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "population",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Population of the country"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return data.Country.Population, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "area",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Area of the country in km²"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return data.Country.Area, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "temperature",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Mean forecast temperature in °C"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return clients.Average(data.Weather.FirstDays(config.DEFAULT_FORECAST_DAYS).Temperatures()), nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "precipitation",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Mean forecast precipitation probability in %"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return clients.Average(data.Weather.FirstDays(config.DEFAULT_FORECAST_DAYS).PrecipitationProbabilities()), nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "targetCurrencies",
+		dependencies: []Upstream{UpstreamCurrency},
+		schema:       FeatureSchema{Type: SchemaStringList, Description: "Exchange rates from the country currencies to the listed currency codes"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return data.Currency, nil
+		},
+	})
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// chartColours are the colours of the lines of a panel, in order
+var chartColours = []string{"#d1495b", "#00798c", "#2e4057", "#edae49", "#66a182"}
+
+// Margins of the plot area inside a panel, in pixels
+const (
+	chartMarginLeft   = 64
+	chartMarginRight  = 16
+	chartMarginTop    = 32
+	chartMarginBottom = 28
+	// chartLabelWidth is the least horizontal space of one x-axis label
+	chartLabelWidth = 48
+)
+
+/*
+RenderChartSVG Draws chart panels stacked from top to bottom as one standalone SVG image of the given size.
+Line panels join the values of every line, bar panels draw the values of every line side by side.
+*/
+func RenderChartSVG(panels []utils.ChartPanel, width int, height int) []byte {
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+
+	if len(panels) > 0 {
+		panelHeight := float64(height) / float64(len(panels))
+		for i, panel := range panels {
+			drawPanel(&svg, panel, float64(i)*panelHeight, float64(width), panelHeight)
+		}
+	}
+
+	svg.WriteString("</svg>\n")
+	return svg.Bytes()
+}
+
+/*
+drawPanel Draws one chart panel with its title, legend, value axis and labels inside the given band of the image
+*/
+func drawPanel(svg *bytes.Buffer, panel utils.ChartPanel, top float64, width float64, height float64) {
+	left := float64(chartMarginLeft)
+	right := width - chartMarginRight
+	plotTop := top + chartMarginTop
+	plotBottom := top + height - chartMarginBottom
+
+	title := panel.Title
+	if panel.Unit != "" {
+		title += " (" + panel.Unit + ")"
+	}
+	fmt.Fprintf(svg, `<text x="%s" y="%s" font-size="14" font-weight="bold">%s</text>`+"\n",
+		coordinate(left), coordinate(top+20), escapeSVG(title))
+
+	// Lines are only told apart by a legend when there are more than one
+	if len(panel.Lines) > 1 {
+		x := right
+		for i := len(panel.Lines) - 1; i >= 0; i-- {
+			x -= float64(len(panel.Lines[i].Name))*7 + 24
+			fmt.Fprintf(svg, `<rect x="%s" y="%s" width="10" height="10" fill="%s"/>`+"\n",
+				coordinate(x), coordinate(top+11), chartColours[i%len(chartColours)])
+			fmt.Fprintf(svg, `<text x="%s" y="%s">%s</text>`+"\n",
+				coordinate(x+14), coordinate(top+20), escapeSVG(panel.Lines[i].Name))
+		}
+	}
+
+	low, high, found := valueRange(panel)
+	if !found || len(panel.Labels) == 0 || plotBottom <= plotTop || right <= left {
+		fmt.Fprintf(svg, `<text x="%s" y="%s" text-anchor="middle" fill="#57606a">No data</text>`+"\n",
+			coordinate((left+right)/2), coordinate((plotTop+plotBottom)/2))
+		return
+	}
+	// Bars grow from zero, so zero is always on the axis of a bar panel
+	if panel.Kind == utils.ChartKindBar {
+		low = math.Min(low, 0)
+		high = math.Max(high, 0)
+	}
+	ticks := axisTicks(low, high)
+	low, high = ticks[0], ticks[len(ticks)-1]
+	y := func(value float64) float64 {
+		return plotBottom - (value-low)/(high-low)*(plotBottom-plotTop)
+	}
+
+	for _, tick := range ticks {
+		fmt.Fprintf(svg, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#d0d7de" stroke-width="1"/>`+"\n",
+			coordinate(left), coordinate(y(tick)), coordinate(right), coordinate(y(tick)))
+		fmt.Fprintf(svg, `<text x="%s" y="%s" text-anchor="end" fill="#57606a">%s</text>`+"\n",
+			coordinate(left-6), coordinate(y(tick)+4), strconv.FormatFloat(tick, 'f', -1, 64))
+	}
+
+	// Every label has a band of its own; only every few labels are written when the bands are narrow
+	band := (right - left) / float64(len(panel.Labels))
+	every := int(math.Ceil(chartLabelWidth / band))
+	for i, label := range panel.Labels {
+		if i%every != 0 {
+			continue
+		}
+		fmt.Fprintf(svg, `<text x="%s" y="%s" text-anchor="middle" fill="#57606a">%s</text>`+"\n",
+			coordinate(left+band*(float64(i)+0.5)), coordinate(plotBottom+18), escapeSVG(shortLabel(label)))
+	}
+
+	for i, line := range panel.Lines {
+		colour := chartColours[i%len(chartColours)]
+		switch panel.Kind {
+		case utils.ChartKindBar:
+			barWidth := band * 0.7 / float64(len(panel.Lines))
+			for j, value := range line.Values {
+				if value == nil || j >= len(panel.Labels) {
+					continue
+				}
+				x := left + band*float64(j) + band*0.15 + barWidth*float64(i)
+				barTop, barBottom := y(math.Max(*value, 0)), y(math.Min(*value, 0))
+				fmt.Fprintf(svg, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"><title>%s: %s</title></rect>`+"\n",
+					coordinate(x), coordinate(barTop), coordinate(barWidth), coordinate(barBottom-barTop), colour,
+					escapeSVG(panel.Labels[j]), strconv.FormatFloat(*value, 'f', -1, 64))
+			}
+		default:
+			// A missing value breaks the line, so the path starts again at the next value
+			var path strings.Builder
+			command := "M"
+			for j, value := range line.Values {
+				if value == nil || j >= len(panel.Labels) {
+					command = "M"
+					continue
+				}
+				fmt.Fprintf(&path, "%s%s %s ", command, coordinate(left+band*(float64(j)+0.5)), coordinate(y(*value)))
+				command = "L"
+			}
+			if path.Len() > 0 {
+				fmt.Fprintf(svg, `<path d="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+					strings.TrimSpace(path.String()), colour)
+			}
+		}
+	}
+}
+
+/*
+valueRange Returns the lowest and highest value of a panel, and whether it has any value at all
+*/
+func valueRange(panel utils.ChartPanel) (float64, float64, bool) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, line := range panel.Lines {
+		for _, value := range line.Values {
+			if value != nil {
+				low = math.Min(low, *value)
+				high = math.Max(high, *value)
+			}
+		}
+	}
+	return low, high, !math.IsInf(low, 1)
+}
+
+/*
+axisTicks Returns evenly spaced round values from at or below low to at or above high, such as -5 0 5 10
+*/
+func axisTicks(low float64, high float64) []float64 {
+	if high == low {
+		low, high = low-1, high+1
+	}
+	// The step is 1, 2 or 5 times a power of ten, giving about five ticks
+	raw := (high - low) / 5
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, factor := range []float64{1, 2, 5} {
+		if raw <= factor*magnitude {
+			step = factor * magnitude
+			break
+		}
+	}
+
+	// Rounded to the decimals of the step, so a tick such as 0.3 is not shown as 0.30000000000000004
+	decimals := int(math.Max(0, -math.Floor(math.Log10(step))))
+	ticks := []float64{}
+	start := math.Floor(low / step)
+	for k := 0.0; k == 0 || (start+k-1)*step < high; k++ {
+		tick, _ := strconv.ParseFloat(strconv.FormatFloat((start+k)*step, 'f', decimals, 64), 64)
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+/*
+shortLabel Shortens a date label such as 2025-04-07 to its month and day, leaving other labels as they are
+*/
+func shortLabel(label string) string {
+	if len(label) == len("2006-01-02") && label[4] == '-' && label[7] == '-' {
+		return label[5:]
+	}
+	return label
+}
+
+/*
+coordinate Formats a coordinate with at most one decimal
+*/
+func coordinate(value float64) string {
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+}
+
+/*
+escapeSVG Escapes text for the content or an attribute of an SVG element
+*/
+func escapeSVG(text string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
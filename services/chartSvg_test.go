@@ -0,0 +1,30 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+/*
+TestAxisTicks tests the value axis of charts, expected result: round steps of 1, 2 or 5 times a power of ten
+covering the whole range, without floating point noise
+*/
+func TestAxisTicks(t *testing.T) {
+	tests := []struct {
+		low      float64
+		high     float64
+		expected []float64
+	}{
+		{-3, 12, []float64{-5, 0, 5, 10, 15}},
+		{0, 100, []float64{0, 20, 40, 60, 80, 100}},
+		{0.1, 0.9, []float64{0, 0.2, 0.4, 0.6, 0.8, 1}},
+		{0.11, 0.24, []float64{0.1, 0.15, 0.2, 0.25}},
+		{5, 5, []float64{4, 4.5, 5, 5.5, 6}},
+	}
+
+	for _, test := range tests {
+		if got := axisTicks(test.low, test.high); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%v to %v: expected %v, got %v", test.low, test.high, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Series that can be charted
+const (
+	ChartTemperature   = "temperature"
+	ChartPrecipitation = "precipitation"
+	ChartRates         = "rates"
+)
+
+// chartSeries lists every series that can be charted, in the order the panels are drawn
+var chartSeries = []string{ChartTemperature, ChartPrecipitation, ChartRates}
+
+/*
+ParseChartSeries Splits a comma separated list of series of a country registration, checking each series. An empty
+list gives the default series of the registration: the weather series, and the rates if it has target currencies.
+*/
+func ParseChartSeries(list string, reg *utils.Dashboard) ([]string, error) {
+	if reg.Type == utils.RegistrationComparison || reg.Type == utils.RegistrationRegion {
+		return nil, fmt.Errorf("charts are only available for %s registrations", utils.RegistrationCountry)
+	}
+
+	requested := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !containsName(chartSeries, name) {
+			return nil, fmt.Errorf("unknown series '%s', expected %s", name, strings.Join(chartSeries, ", "))
+		}
+		requested[name] = true
+	}
+	if len(requested) == 0 {
+		requested[ChartTemperature] = true
+		requested[ChartPrecipitation] = true
+		requested[ChartRates] = len(reg.Features.Strings("targetCurrencies")) > 0
+	}
+	if requested[ChartRates] && len(reg.Features.Strings("targetCurrencies")) == 0 {
+		return nil, errors.New("the registration has no target currencies to chart")
+	}
+
+	series := []string{}
+	for _, name := range chartSeries {
+		if requested[name] {
+			series = append(series, name)
+		}
+	}
+	return series, nil
+}
+
+/*
+BuildChartPanels Fetches the data of the requested series of a country registration and arranges it in chart
+panels: the daily mean, minimum and maximum temperature, the daily precipitation, and the target currency rates
+of the first base currency. The weather follows the unit system of the registration.
+*/
+func BuildChartPanels(ctx context.Context, reg *utils.Dashboard, series []string, days int) ([]utils.ChartPanel, error) {
+	// Only the forecast horizon and the target currencies of the registration are needed
+	chartReg := *reg
+	chartReg.Features = utils.Features{
+		"dailyForecast":    map[string]interface{}{"enabled": true, "days": days},
+		"targetCurrencies": reg.Features.Strings("targetCurrencies"),
+	}
+	needs := map[Upstream]bool{UpstreamCountry: true}
+	if containsName(series, ChartTemperature) || containsName(series, ChartPrecipitation) {
+		needs[UpstreamWeather] = true
+	}
+	if containsName(series, ChartRates) {
+		needs[UpstreamCurrency] = true
+	}
+
+	data, err := fetchUpstreams(ctx, &chartReg, needs)
+	if err != nil {
+		return nil, err
+	}
+
+	units := displayUnits(reg.Display)
+	panels := []utils.ChartPanel{}
+	for _, name := range series {
+		switch name {
+		case ChartTemperature:
+			forecast := data.Weather.FirstDays(forecastDays(chartReg.Features, "dailyForecast"))
+			panels = append(panels, utils.ChartPanel{
+				Title:  "Daily temperature",
+				Unit:   temperatureQuantity.symbol(units),
+				Kind:   utils.ChartKindLine,
+				Labels: forecastDates(forecast),
+				Lines: []utils.ChartLine{
+					{Name: "max", Values: chartValues(forecast, temperatureQuantity, units, func(day utils.DailyForecast) *float64 { return day.TemperatureMax })},
+					{Name: "mean", Values: chartValues(forecast, temperatureQuantity, units, func(day utils.DailyForecast) *float64 { return day.Temperature })},
+					{Name: "min", Values: chartValues(forecast, temperatureQuantity, units, func(day utils.DailyForecast) *float64 { return day.TemperatureMin })},
+				},
+			})
+		case ChartPrecipitation:
+			forecast := data.Weather.FirstDays(forecastDays(chartReg.Features, "dailyForecast"))
+			panels = append(panels, utils.ChartPanel{
+				Title:  "Daily precipitation",
+				Unit:   precipitationQuantity.symbol(units),
+				Kind:   utils.ChartKindBar,
+				Labels: forecastDates(forecast),
+				Lines: []utils.ChartLine{
+					{Name: "precipitation", Values: chartValues(forecast, precipitationQuantity, units, func(day utils.DailyForecast) *float64 { return day.PrecipitationSum })},
+				},
+			})
+		case ChartRates:
+			panels = append(panels, ratesPanel(data.Currency))
+		}
+	}
+	return panels, nil
+}
+
+/*
+ratesPanel Arranges the target currency rates of the first base currency as a bar chart
+*/
+func ratesPanel(groups []utils.GroupedCurrencyResponse) utils.ChartPanel {
+	panel := utils.ChartPanel{Title: "Exchange rates", Kind: utils.ChartKindBar, Labels: []string{}}
+	line := utils.ChartLine{Name: "rate", Values: []*float64{}}
+	if len(groups) > 0 {
+		panel.Title = "Exchange rates from " + groups[0].BaseCode
+		for _, rate := range groups[0].Rates {
+			value := rate.Rate
+			panel.Labels = append(panel.Labels, rate.Code)
+			line.Values = append(line.Values, &value)
+		}
+	}
+	panel.Lines = []utils.ChartLine{line}
+	return panel
+}
+
+/*
+forecastDates Returns the date of every day of a forecast
+*/
+func forecastDates(forecast utils.WeatherForecast) []string {
+	dates := []string{}
+	for _, day := range forecast.Daily {
+		dates = append(dates, day.Date)
+	}
+	return dates
+}
+
+/*
+chartValues Returns one daily value of a forecast for every day, converted into the unit system
+*/
+func chartValues(forecast utils.WeatherForecast, q quantity, units string, value func(day utils.DailyForecast) *float64) []*float64 {
+	values := []*float64{}
+	for _, day := range forecast.Daily {
+		v := value(day)
+		if v != nil && units == utils.UnitsImperial && q.convert != nil {
+			converted := math.Round(q.convert(*v)*100) / 100
+			v = &converted
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+/*
+containsName Checks if a name is in a list
+*/
+func containsName(names []string, name string) bool {
+	for _, existing := range names {
+		if existing == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+/*
+climateYears Returns the number of past years the climate comparison averages, using the default if none is set
+*/
+func climateYears(features utils.Features) int {
+	if years := features.OptionInt("climateComparison", "years"); years > 0 {
+		return years
+	}
+	return config.DEFAULT_CLIMATE_YEARS
+}
+
+/*
+fetchClimate Gets the historical normal for the calendar window of the forecast, at the weather location
+*/
+func fetchClimate(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	forecast := data.Weather.FirstDays(config.DEFAULT_FORECAST_DAYS)
+	if len(forecast.Daily) == 0 {
+		return errors.New("no forecast days to compare with")
+	}
+
+	start, err := time.Parse(time.DateOnly, forecast.Daily[0].Date)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.DateOnly, forecast.Daily[len(forecast.Daily)-1].Date)
+	if err != nil {
+		return err
+	}
+
+	normal, err := clients.GetClimateNormal(ctx, data.WeatherLocation.Latitude, data.WeatherLocation.Longitude,
+		start, end, climateYears(reg.Features))
+	if err != nil {
+		return err
+	}
+	data.Climate = normal
+	return nil
+}
+
+/*
+init Registers the climate comparison feature
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "climateComparison",
+		dependencies: []Upstream{UpstreamWeather, UpstreamClimate},
+		schema: FeatureSchema{
+			Type:        SchemaObject,
+			Description: "Mean forecast temperature compared with the historical mean of the same calendar days",
+			Properties: map[string]FeatureSchema{
+				"enabled": {Type: SchemaBoolean, Description: "Switches the feature on"},
+				"years": {Type: SchemaInteger, Description: "Past years to average, 0 for the default",
+					Minimum: 0, Maximum: config.MAX_CLIMATE_YEARS},
+			},
+		},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(config.DEFAULT_FORECAST_DAYS)
+			normal := data.Climate
+
+			comparison := map[string]interface{}{
+				"startDate":      normal.StartDate,
+				"endDate":        normal.EndDate,
+				"years":          normal.Years,
+				"forecastMean":   nil,
+				"historicalMean": normal.MeanTemperature,
+				"anomaly":        nil,
+				"anomalyPercent": nil,
+			}
+
+			temperatures := forecast.Temperatures()
+			if len(temperatures) == 0 {
+				return comparison, nil
+			}
+			forecastMean := clients.Average(temperatures)
+			comparison["forecastMean"] = forecastMean
+
+			// The anomaly is unknown without history, and the percentage is undefined for a mean of 0 °C
+			if normal.MeanTemperature == nil {
+				return comparison, nil
+			}
+			anomaly := forecastMean - *normal.MeanTemperature
+			comparison["anomaly"] = math.Round(anomaly*100) / 100
+			if *normal.MeanTemperature != 0 {
+				comparison["anomalyPercent"] = math.Round(anomaly/math.Abs(*normal.MeanTemperature)*10000) / 100
+			}
+			return comparison, nil
+		},
+	})
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ValidateRegistrationType Checks the type of a registration, with the countries of a comparison or the region
+of a region dashboard. A comparison needs at least two distinct countries, and neither can have named points.
+*/
+func ValidateRegistrationType(dashboard utils.DashboardPost) error {
+	if dashboard.Type != utils.RegistrationComparison && len(dashboard.Countries) > 0 {
+		return fmt.Errorf("countries are only allowed in a %s registration", utils.RegistrationComparison)
+	}
+
+	switch dashboard.Type {
+	case "", utils.RegistrationCountry:
+		return nil
+	case utils.RegistrationRegion:
+		if len(dashboard.Points) > 0 {
+			return fmt.Errorf("points are not supported in a region")
+		}
+		return validateRegion(dashboard.Region)
+	case utils.RegistrationComparison:
+	default:
+		return fmt.Errorf("unknown registration type '%s', expected %s, %s or %s",
+			dashboard.Type, utils.RegistrationCountry, utils.RegistrationComparison, utils.RegistrationRegion)
+	}
+
+	if len(dashboard.Countries) < 2 || len(dashboard.Countries) > config.MAX_COMPARISON_COUNTRIES {
+		return fmt.Errorf("a comparison needs from 2 to %d countries, got %d", config.MAX_COMPARISON_COUNTRIES, len(dashboard.Countries))
+	}
+	if len(dashboard.Points) > 0 {
+		return fmt.Errorf("points are not supported in a comparison")
+	}
+
+	keys := make(map[string]bool)
+	for i, country := range dashboard.Countries {
+		key := countryKey(country)
+		if key == "" {
+			return fmt.Errorf("country %d needs a country name or an isoCode", i)
+		}
+		if keys[key] {
+			return fmt.Errorf("country '%s' is listed more than once", key)
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+/*
+countryKey Returns the key of a country in a comparison: its ISO code in upper case, or else its name
+*/
+func countryKey(country utils.CountryRef) string {
+	if code := strings.TrimSpace(country.IsoCode); code != "" {
+		return strings.ToUpper(code)
+	}
+	return strings.TrimSpace(country.Country)
+}
+
+/*
+BuildComparison Populates the dashboard of every country of a comparison concurrently with the shared features,
+and ranks the countries by every numeric value
+*/
+func BuildComparison(ctx context.Context, reg *utils.Dashboard) (*utils.ComparisonDashboard, error) {
+	dashboards := make([]*utils.PopulatedDashboard, len(reg.Countries))
+	errs := make([]error, len(reg.Countries))
+	var wg sync.WaitGroup
+	for i, country := range reg.Countries {
+		// Each country is built from a copy of the registration with only its own country set
+		countryReg := *reg
+		countryReg.Type = utils.RegistrationCountry
+		countryReg.Country = country.Country
+		countryReg.IsoCode = country.IsoCode
+		countryReg.Countries = nil
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dashboards[i], errs[i] = BuildDashboard(ctx, &countryReg)
+		}()
+	}
+	wg.Wait()
+
+	comparison := &utils.ComparisonDashboard{
+		Type:          utils.RegistrationComparison,
+		Countries:     make(map[string]*utils.PopulatedDashboard),
+		LastRetrieval: time.Now().Local().String(),
+	}
+	for i, country := range reg.Countries {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("country '%s': %w", countryKey(country), errs[i])
+		}
+		comparison.Countries[countryKey(country)] = dashboards[i]
+	}
+
+	rankings, err := rankCountries(comparison.Countries)
+	if err != nil {
+		return nil, err
+	}
+	comparison.Rankings = rankings
+	return comparison, nil
+}
+
+/*
+rankCountries Ranks the countries by every numeric feature and formula value, highest first. Countries without
+the value are left out of its ranking, and equal values share a rank.
+*/
+func rankCountries(dashboards map[string]*utils.PopulatedDashboard) (map[string][]utils.RankEntry, error) {
+	rankings := make(map[string][]utils.RankEntry)
+	for key, dashboard := range dashboards {
+		values, err := numericValues(dashboard.Features)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range dashboard.Formulas {
+			if value != nil {
+				values["formulas."+name] = *value
+			}
+		}
+		for name, value := range values {
+			rankings[name] = append(rankings[name], utils.RankEntry{Country: key, Value: value})
+		}
+	}
+
+	for _, entries := range rankings {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Value != entries[j].Value {
+				return entries[i].Value > entries[j].Value
+			}
+			return entries[i].Country < entries[j].Country
+		})
+		for i := range entries {
+			entries[i].Rank = i + 1
+			if i > 0 && entries[i].Value == entries[i-1].Value {
+				entries[i].Rank = entries[i-1].Rank
+			}
+		}
+	}
+	return rankings, nil
+}
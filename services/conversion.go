@@ -0,0 +1,106 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// currencyCode is the pattern of an ISO 4217 currency code
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+/*
+ValidateCurrencySetting Checks the base currency and amount of a registration
+*/
+func ValidateCurrencySetting(setting utils.CurrencySetting) error {
+	if setting.Base != "" && !currencyCode.MatchString(strings.ToUpper(setting.Base)) {
+		return fmt.Errorf("base '%s' is not a three-letter currency code", setting.Base)
+	}
+	if setting.Amount < 0 {
+		return fmt.Errorf("amount %f is negative", setting.Amount)
+	}
+	return nil
+}
+
+/*
+ParseCurrencyCodes Splits a comma separated list of currency codes, checking and upper-casing each code
+*/
+func ParseCurrencyCodes(list string) ([]string, error) {
+	codes := []string{}
+	for _, code := range strings.Split(list, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if !currencyCode.MatchString(code) {
+			return nil, fmt.Errorf("'%s' is not a three-letter currency code", code)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+/*
+baseCurrencies Returns the base currencies of the target rates in code order: the base set in the registration,
+or else the currencies of the country
+*/
+func baseCurrencies(reg *utils.Dashboard, country *utils.CountryResponse) []string {
+	if reg.Currency.Base != "" {
+		return []string{strings.ToUpper(reg.Currency.Base)}
+	}
+	codes := []string{}
+	for code := range country.Currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+/*
+convertAmounts Sets the converted amount of every rate, rounded to 2 decimals. Nothing is converted without an amount.
+*/
+func convertAmounts(rates []utils.CurrencyResponse, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	for i := range rates {
+		converted := roundTo(rates[i].Rate*amount, 2)
+		rates[i].Amount = &converted
+	}
+}
+
+/*
+Convert Converts an amount from one currency into the target currencies, using the same cached rate
+tables as the dashboards
+*/
+func Convert(ctx context.Context, from string, to []string, amount float64) (*utils.ConversionResponse, error) {
+	from = strings.ToUpper(from)
+	if !currencyCode.MatchString(from) {
+		return nil, fmt.Errorf("'%s' is not a three-letter currency code", from)
+	}
+	if len(to) == 0 {
+		return nil, errors.New("no target currencies given")
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("amount %f is negative", amount)
+	}
+
+	result, err := clients.GetCurrencyRates(ctx, to, from)
+	if err != nil {
+		return nil, &UpstreamError{Upstream: UpstreamCurrency, Err: err}
+	}
+	convertAmounts(result.Rates, amount)
+
+	return &utils.ConversionResponse{
+		From:              from,
+		Amount:            amount,
+		TimeLastUpdateUTC: result.TimeLastUpdateUTC,
+		TimeNextUpdateUTC: result.TimeNextUpdateUTC,
+		Conversions:       result.Rates,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"context"
+	"sort"
+)
+
+/*
+countryFact Registers a boolean feature that is read directly from the country data
+*/
+func countryFact(name string, description string, value func(country *utils.CountryResponse) interface{}) {
+	RegisterFeature(feature{
+		name:         name,
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: description},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return value(data.Country), nil
+		},
+	})
+}
+
+/*
+callingCodes Joins the international dialling root with each of its suffixes, such as +4 and 7 into +47
+*/
+func callingCodes(country *utils.CountryResponse) []string {
+	codes := []string{}
+	if country.Idd.Root == "" {
+		return codes
+	}
+	if len(country.Idd.Suffixes) == 0 {
+		return append(codes, country.Idd.Root)
+	}
+	for _, suffix := range country.Idd.Suffixes {
+		codes = append(codes, country.Idd.Root+suffix)
+	}
+	return codes
+}
+
+/*
+latestGini Returns the most recent Gini index with its year, or nil if the country has none
+*/
+func latestGini(country *utils.CountryResponse) interface{} {
+	if len(country.Gini) == 0 {
+		return nil
+	}
+	years := make([]string, 0, len(country.Gini))
+	for year := range country.Gini {
+		years = append(years, year)
+	}
+	sort.Strings(years)
+	latest := years[len(years)-1]
+	return map[string]interface{}{
+		"year":  latest,
+		"index": country.Gini[latest],
+	}
+}
+
+/*
+emptyIfNil Returns an empty list instead of nil, so missing lists are shown as [] rather than null
+*/
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+/*
+init Registers the country fact features
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "names",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Common and official name of the country, translated if a lang is set"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			common, official := translatedNames(data.Country, reg.Display.Lang)
+			return map[string]string{
+				"common":   common,
+				"official": official,
+			}, nil
+		},
+	})
+
+	countryFact("region", "Region and subregion of the country", func(country *utils.CountryResponse) interface{} {
+		return map[string]string{
+			"region":    country.Region,
+			"subregion": country.Subregion,
+		}
+	})
+
+	countryFact("languages", "Languages spoken, by ISO 639-3 code", func(country *utils.CountryResponse) interface{} {
+		if country.Languages == nil {
+			return map[string]string{}
+		}
+		return country.Languages
+	})
+
+	countryFact("borders", "ISO 3166-1 alpha-3 codes of the bordering countries", func(country *utils.CountryResponse) interface{} {
+		return emptyIfNil(country.Borders)
+	})
+
+	countryFact("timezones", "Time zones of the country as UTC offsets", func(country *utils.CountryResponse) interface{} {
+		return emptyIfNil(country.Timezones)
+	})
+
+	countryFact("callingCodes", "International calling codes", func(country *utils.CountryResponse) interface{} {
+		return callingCodes(country)
+	})
+
+	countryFact("topLevelDomains", "Internet top-level domains", func(country *utils.CountryResponse) interface{} {
+		return emptyIfNil(country.Tld)
+	})
+
+	countryFact("flag", "Flag images and emoji", func(country *utils.CountryResponse) interface{} {
+		return map[string]string{
+			"emoji": country.Flag,
+			"png":   country.Flags.Png,
+			"svg":   country.Flags.Svg,
+			"alt":   country.Flags.Alt,
+		}
+	})
+
+	countryFact("drivingSide", "Side of the road traffic drives on", func(country *utils.CountryResponse) interface{} {
+		return country.Car.Side
+	})
+
+	countryFact("gini", "Most recent Gini index of income inequality, with its year", latestGini)
+
+	countryFact("unMember", "Membership of the United Nations", func(country *utils.CountryResponse) interface{} {
+		return country.UnMember
+	})
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+/*
+BuildDashboard Populates a dashboard from a registration. Only the upstreams needed by the enabled
+features are fetched, after which every enabled feature is resolved from the fetched data. The weather
+features are also resolved for every named point of the registration, and the formulas of the registration
+are computed from the resolved values.
+*/
+func BuildDashboard(ctx context.Context, reg *utils.Dashboard) (*utils.PopulatedDashboard, error) {
+	providers := EnabledFeatures(reg.Features)
+
+	// Features only read by the formulas are resolved too, but not shown
+	resolved := append([]FeatureProvider{}, providers...)
+	for _, provider := range formulaFeatures(reg.Formulas) {
+		if !containsFeature(resolved, provider.Name()) {
+			resolved = append(resolved, provider)
+		}
+	}
+
+	// The named points are only looked up if there are weather features to show for them
+	needs := RequiredUpstreams(resolved)
+	if len(reg.Points) > 0 && RequiredUpstreams(providers)[UpstreamWeather] {
+		needs[UpstreamPoints] = true
+	}
+	// The translated name of the country is read from the country data
+	if reg.Display.Lang != "" {
+		needs[UpstreamCountry] = true
+	}
+
+	data, err := fetchUpstreams(ctx, reg, needs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assemble the features based on the configuration in the database
+	values := make(map[string]interface{})
+	for _, provider := range resolved {
+		value, err := provider.Resolve(ctx, reg, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve feature %s: %w", provider.Name(), err)
+		}
+		values[provider.Name()] = value
+	}
+	featuresMap := make(map[string]interface{})
+	for _, provider := range providers {
+		featuresMap[provider.Name()] = values[provider.Name()]
+	}
+
+	dashboard := &utils.PopulatedDashboard{
+		Country:       reg.Country,
+		IsoCode:       reg.IsoCode,
+		Features:      featuresMap,
+		Units:         displayUnits(reg.Display),
+		LastRetrieval: time.Now().Local().String(),
+	}
+	if reg.Display.Lang != "" {
+		dashboard.Country, _ = translatedNames(data.Country, reg.Display.Lang)
+	}
+	if data.Weather != nil {
+		dashboard.WeatherLocation = &data.WeatherLocation
+	}
+	if data.Points != nil {
+		dashboard.Points, err = resolvePoints(ctx, reg, providers, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Formulas are computed from the metric values, whatever the unit system of the dashboard
+	if len(reg.Formulas) > 0 {
+		dashboard.Formulas, err = resolveFormulas(reg.Formulas, values, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := applyDisplay(dashboard, reg.Display); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+/*
+applyDisplay Converts the features of a dashboard and its named points into its unit system, and adds the
+display strings of the features if a locale is set
+*/
+func applyDisplay(dashboard *utils.PopulatedDashboard, setting utils.DisplaySetting) error {
+	if dashboard.Units == utils.UnitsImperial {
+		if err := convertUnits(dashboard.Features, dashboardQuantities, dashboard.Units, &dashboard.Features); err != nil {
+			return err
+		}
+		for i := range dashboard.Points {
+			err := convertUnits(dashboard.Points[i].Features, dashboardQuantities, dashboard.Units, &dashboard.Points[i].Features)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if setting.Locale != "" {
+		formatted, err := formatValues(dashboard.Features, dashboardQuantities, dashboard.Units, setting.Locale)
+		if err != nil {
+			return err
+		}
+		dashboard.Formatted = formatted
+	}
+	return nil
+}
+
+/*
+containsFeature Checks if a feature is among the given providers
+*/
+func containsFeature(providers []FeatureProvider, name string) bool {
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return true
+		}
+	}
+	return false
+}
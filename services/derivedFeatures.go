@@ -0,0 +1,130 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"math"
+	"sort"
+)
+
+// referenceCurrencies are the currencies that target rates are normalised to
+var referenceCurrencies = []string{"EUR", "USD"}
+
+/*
+fetchReferenceRates Gets the rates from every base currency to the reference currencies
+*/
+func fetchReferenceRates(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	references := make(map[string]map[string]float64)
+	for _, code := range baseCurrencies(reg, data.Country) {
+		result, err := clients.GetCurrencyRates(ctx, referenceCurrencies, code)
+		if err != nil {
+			return err
+		}
+		rates := make(map[string]float64)
+		for _, rate := range result.Rates {
+			rates[rate.Code] = rate.Rate
+		}
+		references[code] = rates
+	}
+	data.ReferenceRates = references
+	return nil
+}
+
+/*
+roundTo Rounds a value to the given number of decimal places
+*/
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
+
+/*
+normalisedRates Expresses every target rate as units of the target currency per unit of each reference
+currency, grouped by base currency. A rate is nil when the reference rate is missing or zero.
+*/
+func normalisedRates(groups []utils.GroupedCurrencyResponse, references map[string]map[string]float64) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	for _, group := range groups {
+		rates := make(map[string]map[string]*float64)
+		for _, target := range group.Rates {
+			normalised := make(map[string]*float64)
+			for _, reference := range referenceCurrencies {
+				normalised[reference] = nil
+				referenceRate, ok := references[group.BaseCode][reference]
+				if group.BaseCode == reference {
+					referenceRate, ok = 1, true
+				}
+				if ok && referenceRate != 0 {
+					value := roundTo(target.Rate/referenceRate, 4)
+					normalised[reference] = &value
+				}
+			}
+			rates[target.Code] = normalised
+		}
+		result = append(result, map[string]interface{}{
+			"baseCode": group.BaseCode,
+			"rates":    rates,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i]["baseCode"].(string) < result[j]["baseCode"].(string)
+	})
+	return result
+}
+
+/*
+init Registers the features derived from the combined upstream data
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "populationDensity",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Inhabitants per km², rounded to 2 decimals"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			// The density is unknown for a country without a known area
+			if data.Country.Area <= 0 {
+				return nil, nil
+			}
+			return roundTo(float64(data.Country.Population)/data.Country.Area, 2), nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "worldPopulationShare",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Share of the world population in %, rounded to 4 decimals"},
+		scalar:       true,
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return roundTo(float64(data.Country.Population)/config.WORLD_POPULATION*100, 4), nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "normalisedRates",
+		dependencies: []Upstream{UpstreamCurrency, UpstreamReferenceRates},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Target currency rates per EUR and per USD, rounded to 4 decimals"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return normalisedRates(data.Currency, data.ReferenceRates), nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "temperatureScales",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Mean forecast temperature in °C and °F, rounded to 2 decimals"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			temperatures := data.Weather.FirstDays(config.DEFAULT_FORECAST_DAYS).Temperatures()
+			if len(temperatures) == 0 {
+				return map[string]interface{}{"celsius": nil, "fahrenheit": nil}, nil
+			}
+			celsius := clients.Average(temperatures)
+			return map[string]interface{}{
+				"celsius":    celsius,
+				"fahrenheit": roundTo(celsius*9/5+32, 2),
+			}, nil
+		},
+	})
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// translationCode is the pattern of a REST Countries translation code, such as deu or fra
+var translationCode = regexp.MustCompile(`^[a-z]{3}$`)
+
+/*
+numberFormat The decimal and group separators of a locale
+*/
+type numberFormat struct {
+	decimal string
+	group   string
+}
+
+// numberFormats holds the number format of every supported language
+var numberFormats = map[string]numberFormat{
+	"en": {".", ","},
+	"ja": {".", ","},
+	"ko": {".", ","},
+	"zh": {".", ","},
+	"de": {",", "."},
+	"da": {",", "."},
+	"es": {",", "."},
+	"id": {",", "."},
+	"it": {",", "."},
+	"nl": {",", "."},
+	"pt": {",", "."},
+	"tr": {",", "."},
+	"cs": {",", " "},
+	"fi": {",", " "},
+	"fr": {",", " "},
+	"nb": {",", " "},
+	"nn": {",", " "},
+	"no": {",", " "},
+	"pl": {",", " "},
+	"ru": {",", " "},
+	"sv": {",", " "},
+	"uk": {",", " "},
+}
+
+/*
+localeFormat Returns the number format of a language tag, such as de-DE or nb_NO, by its language
+*/
+func localeFormat(locale string) (numberFormat, bool) {
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	format, exists := numberFormats[strings.ToLower(language)]
+	return format, exists
+}
+
+/*
+ValidateDisplaySetting Checks the unit system, locale and translation language of a registration or request
+*/
+func ValidateDisplaySetting(setting utils.DisplaySetting) error {
+	if setting.Units != "" && setting.Units != utils.UnitsMetric && setting.Units != utils.UnitsImperial {
+		return fmt.Errorf("units must be %s or %s, got '%s'", utils.UnitsMetric, utils.UnitsImperial, setting.Units)
+	}
+	if setting.Locale != "" {
+		if _, exists := localeFormat(setting.Locale); !exists {
+			return fmt.Errorf("locale '%s' is not supported", setting.Locale)
+		}
+	}
+	if setting.Lang != "" && !translationCode.MatchString(setting.Lang) {
+		return fmt.Errorf("lang '%s' is not a three-letter translation code", setting.Lang)
+	}
+	return nil
+}
+
+/*
+formatNumber Formats a number with at most 2 decimals and the separators of a locale, such as 5.379.475 or 3,14
+*/
+func formatNumber(value float64, format numberFormat) string {
+	text := strconv.FormatFloat(math.Abs(value), 'f', 2, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+	fraction = strings.TrimRight(fraction, "0")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(format.group)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	formatted := grouped.String()
+	if fraction != "" {
+		formatted += format.decimal + fraction
+	}
+	// Values that round to zero are shown without a sign
+	if value < 0 && strings.Trim(whole+fraction, "0") != "" {
+		formatted = "-" + formatted
+	}
+	return formatted
+}
+
+/*
+formatValues Formats every number among the values, except those in lists, with the separators of a locale
+and its unit symbol. The display strings are keyed by the dotted path of their value.
+*/
+func formatValues(values interface{}, quantities map[string]quantity, units string, locale string) (map[string]string, error) {
+	format, exists := localeFormat(locale)
+	if !exists {
+		return nil, fmt.Errorf("locale '%s' is not supported", locale)
+	}
+	numbers, err := numericValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted := make(map[string]string)
+	for path, value := range numbers {
+		text := formatNumber(value, format)
+		if q, exists := quantities[path]; exists {
+			// Per-area symbols such as /km² follow the number directly
+			if symbol := q.symbol(units); strings.HasPrefix(symbol, "/") {
+				text += symbol
+			} else {
+				text += " " + symbol
+			}
+		}
+		formatted[path] = text
+	}
+	return formatted, nil
+}
+
+/*
+translatedNames Returns the common and official name of a country in a REST Countries translation, falling
+back to the English names if there is no translation for the code
+*/
+func translatedNames(country *utils.CountryResponse, lang string) (string, string) {
+	if translation, exists := country.Translations[lang]; exists && translation.Common != "" {
+		return translation.Common, translation.Official
+	}
+	return country.Name.Common, country.Name.Official
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"testing"
+)
+
+/*
+TestFormatNumber tests the display strings of numbers in locales, expected result: the separators of the
+locale, at most 2 decimals and no sign on a value that rounds to zero
+*/
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value    float64
+		locale   string
+		expected string
+	}{
+		{5379475, "en-GB", "5,379,475"},
+		{5379475, "de-DE", "5.379.475"},
+		{5379475, "nb_NO", "5\u00a0379\u00a0475"}, // Grouped by no-break spaces
+		{3.14159, "de", "3,14"},
+		{-1234.5, "en", "-1,234.5"},
+		{-0.001, "en", "0"},
+		{100, "fr", "100"},
+	}
+
+	for _, test := range tests {
+		format, exists := localeFormat(test.locale)
+		if !exists {
+			t.Fatalf("Expected locale %s to be supported", test.locale)
+		}
+		if got := formatNumber(test.value, format); got != test.expected {
+			t.Errorf("%v in %s: expected %s, got %s", test.value, test.locale, test.expected, got)
+		}
+	}
+}
+
+/*
+TestValidateDisplaySetting tests the checks of display settings, expected result: unknown unit systems,
+locales and malformed translation codes are rejected
+*/
+func TestValidateDisplaySetting(t *testing.T) {
+	tests := []struct {
+		setting utils.DisplaySetting
+		valid   bool
+	}{
+		{utils.DisplaySetting{}, true},
+		{utils.DisplaySetting{Units: utils.UnitsImperial, Locale: "de-DE", Lang: "deu"}, true},
+		{utils.DisplaySetting{Units: "kelvin"}, false},
+		{utils.DisplaySetting{Locale: "xx-XX"}, false},
+		{utils.DisplaySetting{Lang: "de"}, false},
+	}
+
+	for _, test := range tests {
+		err := ValidateDisplaySetting(test.setting)
+		if test.valid != (err == nil) {
+			t.Errorf("%+v: expected valid %t, got %v", test.setting, test.valid, err)
+		}
+	}
+}
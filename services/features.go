@@ -0,0 +1,227 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+FeatureProvider A dashboard feature that can be enabled by name in a registration
+*/
+type FeatureProvider interface {
+	// Name is the key of the feature in registrations and populated dashboards
+	Name() string
+	// Dependencies lists the upstream data the feature is computed from
+	Dependencies() []Upstream
+	// Schema describes the configuration value accepted in registrations
+	Schema() FeatureSchema
+	// Scalar reports whether the dashboard value is a single value without properties, such as the population
+	Scalar() bool
+	// Resolve computes the dashboard value of the feature from the fetched upstream data
+	Resolve(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error)
+}
+
+// Configuration value types a feature can accept in a registration
+const (
+	SchemaBoolean    = "boolean"
+	SchemaInteger    = "integer"
+	SchemaStringList = "stringList"
+	// SchemaObject is an options object, where the feature is switched on by its "enabled" property
+	SchemaObject = "object"
+)
+
+/*
+FeatureSchema Describes the configuration value of a feature in a registration
+*/
+type FeatureSchema struct {
+	Type        string                   `json:"type"`
+	Description string                   `json:"description"`
+	Properties  map[string]FeatureSchema `json:"properties,omitempty"` // Accepted properties of an object
+	Minimum     int                      `json:"minimum,omitempty"`    // Bounds of an integer
+	Maximum     int                      `json:"maximum,omitempty"`
+}
+
+/*
+Validate Checks that a decoded JSON value matches the schema
+*/
+func (s FeatureSchema) Validate(value interface{}) error {
+	switch s.Type {
+	case SchemaBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean")
+		}
+	case SchemaInteger:
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("expected an integer")
+		}
+		if int(number) < s.Minimum || int(number) > s.Maximum {
+			return fmt.Errorf("expected an integer from %d to %d", s.Minimum, s.Maximum)
+		}
+	case SchemaObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object")
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			schema, exists := s.Properties[key]
+			if !exists {
+				return fmt.Errorf("unknown property '%s'", key)
+			}
+			if err := schema.Validate(object[key]); err != nil {
+				return fmt.Errorf("property '%s': %w", key, err)
+			}
+		}
+	case SchemaStringList:
+		// A missing list is stored as null
+		if value == nil {
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of strings")
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("expected a list of strings")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown schema type %s", s.Type)
+	}
+	return nil
+}
+
+/*
+Enabled Reports whether a decoded JSON value switches the feature on
+*/
+func (s FeatureSchema) Enabled(value interface{}) bool {
+	switch s.Type {
+	case SchemaBoolean:
+		enabled, _ := value.(bool)
+		return enabled
+	case SchemaStringList:
+		switch list := value.(type) {
+		case []interface{}:
+			return len(list) > 0
+		case []string:
+			return len(list) > 0
+		}
+	case SchemaObject:
+		object, _ := value.(map[string]interface{})
+		enabled, _ := object["enabled"].(bool)
+		return enabled
+	}
+	return false
+}
+
+// featureRegistry holds every available dashboard feature by name
+var featureRegistry = make(map[string]FeatureProvider)
+
+/*
+RegisterFeature Adds a feature provider to the registry. Registering a name twice is a programming error.
+*/
+func RegisterFeature(provider FeatureProvider) {
+	if _, exists := featureRegistry[provider.Name()]; exists {
+		panic("feature already registered: " + provider.Name())
+	}
+	featureRegistry[provider.Name()] = provider
+}
+
+/*
+GetFeature Looks up a feature provider by name
+*/
+func GetFeature(name string) (FeatureProvider, bool) {
+	provider, ok := featureRegistry[name]
+	return provider, ok
+}
+
+/*
+AllFeatures Returns every registered feature provider sorted by name
+*/
+func AllFeatures() []FeatureProvider {
+	providers := make([]FeatureProvider, 0, len(featureRegistry))
+	for _, provider := range featureRegistry {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name() < providers[j].Name()
+	})
+	return providers
+}
+
+/*
+ValidateFeatures Checks that every key of a decoded features object is a registered feature
+and that its value matches the schema of that feature
+*/
+func ValidateFeatures(features map[string]interface{}) error {
+	// Sorted so the same payload always reports the same error
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		provider, ok := GetFeature(name)
+		if !ok {
+			return fmt.Errorf("unknown feature '%s'", name)
+		}
+		if err := provider.Schema().Validate(features[name]); err != nil {
+			return fmt.Errorf("invalid value for feature '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+/*
+EnabledFeatures Returns the providers of the features switched on in a registration, sorted by name
+*/
+func EnabledFeatures(features utils.Features) []FeatureProvider {
+	var enabled []FeatureProvider
+	for _, provider := range AllFeatures() {
+		if value, ok := features[provider.Name()]; ok && provider.Schema().Enabled(value) {
+			enabled = append(enabled, provider)
+		}
+	}
+	return enabled
+}
+
+/*
+feature A FeatureProvider built from plain values and a resolve function
+*/
+type feature struct {
+	name         string
+	dependencies []Upstream
+	schema       FeatureSchema
+	scalar       bool
+	resolve      func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error)
+}
+
+func (f feature) Name() string {
+	return f.name
+}
+
+func (f feature) Dependencies() []Upstream {
+	return f.dependencies
+}
+
+func (f feature) Schema() FeatureSchema {
+	return f.schema
+}
+
+func (f feature) Scalar() bool {
+	return f.scalar
+}
+
+func (f feature) Resolve(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+	return f.resolve(ctx, reg, data)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"testing"
+)
+
+/*
+TestFeatureSchemaValidate tests the schema of every kind of feature value against valid and invalid decoded
+JSON values, expected result: only values of the right type and within bounds are accepted
+*/
+func TestFeatureSchemaValidate(t *testing.T) {
+	forecast := forecastSchema("forecast")
+	tests := []struct {
+		name   string
+		schema FeatureSchema
+		value  interface{}
+		valid  bool
+	}{
+		{"boolean", FeatureSchema{Type: SchemaBoolean}, true, true},
+		{"boolean as string", FeatureSchema{Type: SchemaBoolean}, "yes", false},
+		{"string list", FeatureSchema{Type: SchemaStringList}, []interface{}{"EUR", "USD"}, true},
+		{"missing string list", FeatureSchema{Type: SchemaStringList}, nil, true},
+		{"string list with number", FeatureSchema{Type: SchemaStringList}, []interface{}{"EUR", 1.0}, false},
+		{"string as list", FeatureSchema{Type: SchemaStringList}, "EUR", false},
+		{"options", forecast, map[string]interface{}{"enabled": true, "days": 3.0}, true},
+		{"options without enabled", forecast, map[string]interface{}{"days": 3.0}, true},
+		{"options with fraction", forecast, map[string]interface{}{"days": 2.5}, false},
+		{"options out of bounds", forecast, map[string]interface{}{"days": 17.0}, false},
+		{"options with unknown property", forecast, map[string]interface{}{"hours": 3.0}, false},
+		{"boolean as options", forecast, true, false},
+	}
+
+	for _, test := range tests {
+		err := test.schema.Validate(test.value)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+/*
+TestValidateFeatures tests a features object against the feature registry, expected result: unknown features
+and wrong-typed values are rejected
+*/
+func TestValidateFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		features map[string]interface{}
+		valid    bool
+	}{
+		{"known features", map[string]interface{}{"capital": true, "targetCurrencies": []interface{}{"EUR"}}, true},
+		{"unknown feature", map[string]interface{}{"volcanoes": true}, false},
+		{"wrong type", map[string]interface{}{"temperature": "yes"}, false},
+		{"no features", nil, true},
+	}
+
+	for _, test := range tests {
+		err := ValidateFeatures(test.features)
+		if test.valid != (err == nil) {
+			t.Errorf("%s: expected valid %t, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+/*
+TestEnabledFeatures tests which features of a registration are switched on, expected result: true booleans,
+non-empty lists and options with enabled set, sorted by name
+*/
+func TestEnabledFeatures(t *testing.T) {
+	features := utils.Features{
+		"capital":          true,
+		"area":             false,
+		"targetCurrencies": []string{"EUR"},
+		"dailyForecast":    map[string]interface{}{"enabled": true, "days": 3},
+		"wind":             map[string]interface{}{"days": 3},
+		"humidity":         map[string]interface{}{"enabled": false},
+	}
+
+	var names []string
+	for _, provider := range EnabledFeatures(features) {
+		names = append(names, provider.Name())
+	}
+	expected := []string{"capital", "dailyForecast", "targetCurrencies"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, names)
+		}
+	}
+}
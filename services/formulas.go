@@ -0,0 +1,317 @@
+package services
+
+import (
+	"assignment-2/config"
+	"assignment-2/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// formulaRates is the variable prefix of the target currency rates, such as rates.EUR
+const formulaRates = "rates"
+
+// formulaName is the pattern of a formula name, which becomes its key in the dashboard
+var formulaName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+/*
+ParseFormula Parses an arithmetic expression. Only decimal numbers, variables such as population or rates.EUR,
+parentheses and the operators + - * / are accepted, so evaluating a formula can never do anything else.
+*/
+func ParseFormula(expression string) (ast.Expr, error) {
+	if len(expression) > config.MAX_FORMULA_EXPRESSION {
+		return nil, fmt.Errorf("expression is longer than %d characters", config.MAX_FORMULA_EXPRESSION)
+	}
+	expr, err := parser.ParseExpr(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	var check func(node ast.Expr) error
+	check = func(node ast.Expr) error {
+		switch n := node.(type) {
+		case *ast.BasicLit:
+			if n.Kind != token.INT && n.Kind != token.FLOAT {
+				return fmt.Errorf("unsupported literal %s", n.Value)
+			}
+			// Go accepts numbers such as 0x10 or 0b11 that are not read when the formula is evaluated
+			if _, err := strconv.ParseFloat(n.Value, 64); err != nil {
+				return fmt.Errorf("unsupported number %s, only decimal numbers are allowed", n.Value)
+			}
+		case *ast.Ident, *ast.SelectorExpr:
+			if _, err := variableName(n); err != nil {
+				return err
+			}
+		case *ast.ParenExpr:
+			return check(n.X)
+		case *ast.UnaryExpr:
+			if n.Op != token.ADD && n.Op != token.SUB {
+				return fmt.Errorf("unsupported operator %s", n.Op)
+			}
+			return check(n.X)
+		case *ast.BinaryExpr:
+			if n.Op != token.ADD && n.Op != token.SUB && n.Op != token.MUL && n.Op != token.QUO {
+				return fmt.Errorf("unsupported operator %s", n.Op)
+			}
+			if err := check(n.X); err != nil {
+				return err
+			}
+			return check(n.Y)
+		default:
+			return errors.New("only numbers, variables, parentheses and + - * / are allowed")
+		}
+		return nil
+	}
+	if err := check(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+/*
+variableName Returns the dotted name of a variable, such as climateComparison.anomaly
+*/
+func variableName(node ast.Expr) (string, error) {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return n.Name, nil
+	case *ast.SelectorExpr:
+		prefix, err := variableName(n.X)
+		if err != nil {
+			return "", err
+		}
+		return prefix + "." + n.Sel.Name, nil
+	default:
+		return "", errors.New("invalid variable")
+	}
+}
+
+/*
+formulaVariables Lists the variables used in a parsed formula
+*/
+func formulaVariables(expr ast.Expr) []string {
+	var variables []string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+			name, _ := variableName(n.(ast.Expr))
+			variables = append(variables, name)
+			// The parts of a dotted name are not variables of their own
+			return false
+		}
+		return true
+	})
+	return variables
+}
+
+/*
+variableFeature Returns the name of the feature a variable is read from
+*/
+func variableFeature(variable string) string {
+	root, _, _ := strings.Cut(variable, ".")
+	if root == formulaRates {
+		return "targetCurrencies"
+	}
+	return root
+}
+
+/*
+ValidateFormulas Checks the formulas of a registration: every formula needs a unique name and a valid
+expression over known features. A variable can only have a dotted path under a feature with properties, and
+the rates used must be among the target currencies.
+*/
+func ValidateFormulas(formulas []utils.Formula, features utils.Features) error {
+	if len(formulas) > config.MAX_FORMULAS {
+		return fmt.Errorf("at most %d formulas are allowed, got %d", config.MAX_FORMULAS, len(formulas))
+	}
+
+	targets := make(map[string]bool)
+	for _, code := range features.Strings("targetCurrencies") {
+		targets[strings.ToUpper(code)] = true
+	}
+
+	names := make(map[string]bool)
+	for i, formula := range formulas {
+		if !formulaName.MatchString(formula.Name) {
+			return fmt.Errorf("formula %d needs a name of letters, digits and underscores", i)
+		}
+		if names[formula.Name] {
+			return fmt.Errorf("name '%s' is used by more than one formula", formula.Name)
+		}
+		names[formula.Name] = true
+
+		expr, err := ParseFormula(formula.Expression)
+		if err != nil {
+			return fmt.Errorf("formula '%s': %w", formula.Name, err)
+		}
+		for _, variable := range formulaVariables(expr) {
+			provider, exists := GetFeature(variableFeature(variable))
+			if !exists {
+				return fmt.Errorf("formula '%s': unknown variable '%s'", formula.Name, variable)
+			}
+			root, _, dotted := strings.Cut(variable, ".")
+			// Only rates.CODE is ever given a value, and a single value has no properties to read
+			if root == formulaRates && !dotted {
+				return fmt.Errorf("formula '%s': %s needs a currency code, such as %s.EUR", formula.Name, formulaRates, formulaRates)
+			}
+			if dotted && provider.Scalar() {
+				return fmt.Errorf("formula '%s': unknown variable '%s', %s has no properties", formula.Name, variable, root)
+			}
+			if code, isRate := strings.CutPrefix(variable, formulaRates+"."); isRate && !targets[strings.ToUpper(code)] {
+				return fmt.Errorf("formula '%s': %s is not one of the target currencies", formula.Name, code)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+formulaFeatures Returns the features the formulas of a registration read from
+*/
+func formulaFeatures(formulas []utils.Formula) []FeatureProvider {
+	seen := make(map[string]bool)
+	var providers []FeatureProvider
+	for _, formula := range formulas {
+		expr, err := ParseFormula(formula.Expression)
+		if err != nil {
+			continue
+		}
+		for _, variable := range formulaVariables(expr) {
+			name := variableFeature(variable)
+			if provider, exists := GetFeature(name); exists && !seen[name] {
+				seen[name] = true
+				providers = append(providers, provider)
+			}
+		}
+	}
+	return providers
+}
+
+/*
+numericValues Collects every number among resolved values under its dotted path, such as
+climateComparison.anomaly. Lists and other values are left out.
+*/
+func numericValues(values interface{}) (map[string]float64, error) {
+	// A JSON round trip turns every resolved value into plain maps and float64 numbers
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+
+	numbers := make(map[string]float64)
+	var collect func(prefix string, value interface{})
+	collect = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case float64:
+			numbers[prefix] = v
+		case map[string]interface{}:
+			for key, nested := range v {
+				collect(prefix+"."+key, nested)
+			}
+		}
+	}
+	for name, value := range decoded {
+		collect(name, value)
+	}
+	return numbers, nil
+}
+
+/*
+formulaValues Collects the numeric values a formula can use: every number among the resolved features under
+its dotted path, and the rates from the first base currency under rates.CODE
+*/
+func formulaValues(values map[string]interface{}, data *UpstreamData) (map[string]float64, error) {
+	variables, err := numericValues(values)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Currency) > 0 {
+		for _, rate := range data.Currency[0].Rates {
+			variables[formulaRates+"."+rate.Code] = rate.Rate
+		}
+	}
+	return variables, nil
+}
+
+/*
+evaluateFormula Computes a parsed formula. The result is nil if a variable has no value, or if the
+result is not a finite number, such as after a division by zero.
+*/
+func evaluateFormula(expr ast.Expr, variables map[string]float64) *float64 {
+	var eval func(node ast.Expr) (float64, bool)
+	eval = func(node ast.Expr) (float64, bool) {
+		switch n := node.(type) {
+		case *ast.BasicLit:
+			value, err := strconv.ParseFloat(n.Value, 64)
+			return value, err == nil
+		case *ast.Ident, *ast.SelectorExpr:
+			name, _ := variableName(n)
+			value, ok := variables[name]
+			return value, ok
+		case *ast.ParenExpr:
+			return eval(n.X)
+		case *ast.UnaryExpr:
+			value, ok := eval(n.X)
+			if n.Op == token.SUB {
+				value = -value
+			}
+			return value, ok
+		case *ast.BinaryExpr:
+			x, okX := eval(n.X)
+			y, okY := eval(n.Y)
+			if !okX || !okY {
+				return 0, false
+			}
+			switch n.Op {
+			case token.ADD:
+				return x + y, true
+			case token.SUB:
+				return x - y, true
+			case token.MUL:
+				return x * y, true
+			case token.QUO:
+				return x / y, true
+			}
+		}
+		return 0, false
+	}
+
+	value, ok := eval(expr)
+	if !ok || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
+
+/*
+resolveFormulas Computes every formula of a registration from the resolved feature values
+*/
+func resolveFormulas(formulas []utils.Formula, values map[string]interface{}, data *UpstreamData) (map[string]*float64, error) {
+	variables, err := formulaValues(values, data)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*float64)
+	for _, formula := range formulas {
+		expr, err := ParseFormula(formula.Expression)
+		if err != nil {
+			// Formulas are validated when registered, so this is only reached by stored data that was changed
+			results[formula.Name] = nil
+			continue
+		}
+		results[formula.Name] = evaluateFormula(expr, variables)
+	}
+	return results, nil
+}
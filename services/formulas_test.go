@@ -0,0 +1,126 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"strings"
+	"testing"
+)
+
+/*
+TestParseFormula tests which expressions are accepted as formulas, expected result: only decimal numbers,
+variables, parentheses and + - * / are accepted, so evaluating a formula can never run anything else and every
+accepted number is read when it is evaluated
+*/
+func TestParseFormula(t *testing.T) {
+	tests := []struct {
+		expression string
+		valid      bool
+	}{
+		{"population / area * 1000", true},
+		{"-(temperature - 2.5) * rates.EUR", true},
+		{"climateComparison.anomaly + 1e3", true},
+		{"0.5 * 07", true},
+		{"0x10 * population", false},
+		{"0b11 * population", false},
+		{"0o7 * population", false},
+		{"1_000 * population", true},
+		{"2i * population", false},
+		{"os.Exit(1)", false},
+		{"len(population)", false},
+		{"rates[0]", false},
+		{"population[1:2]", false},
+		{`"population"`, false},
+		{"'p'", false},
+		{"population % 7", false},
+		{"population << 2", false},
+		{"population == area", false},
+		{"!population", false},
+		{"^population", false},
+		{"func() {}", false},
+		{"population +", false},
+		{strings.Repeat("1+", 200) + "1", false},
+	}
+
+	for _, test := range tests {
+		_, err := ParseFormula(test.expression)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", test.expression, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.expression)
+		}
+	}
+}
+
+/*
+TestEvaluateFormula tests the computation of formulas, expected result: the arithmetic value, and nil for a
+missing variable or a result that is not a finite number
+*/
+func TestEvaluateFormula(t *testing.T) {
+	variables := map[string]float64{"population": 5000000, "area": 250000, "rates.EUR": 0.09, "zero": 0}
+	tests := []struct {
+		expression string
+		expected   *float64
+	}{
+		{"population / area", floatPointer(20)},
+		{"-(1 + 2) * 3", floatPointer(-9)},
+		{"rates.EUR * 100", floatPointer(9)},
+		{"1e3 + .5 + 007", floatPointer(1007.5)},
+		{"population / zero", nil},
+		{"zero / zero", nil},
+		{"-population / (area - area)", nil},
+		{"gini * 2", nil},
+	}
+
+	for _, test := range tests {
+		expr, err := ParseFormula(test.expression)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expression, err)
+		}
+		got := evaluateFormula(expr, variables)
+		switch {
+		case test.expected == nil && got != nil:
+			t.Errorf("%s: expected nil, got %v", test.expression, *got)
+		case test.expected != nil && (got == nil || *got != *test.expected):
+			t.Errorf("%s: expected %v, got %v", test.expression, *test.expected, got)
+		}
+	}
+}
+
+/*
+TestValidateFormulas tests the formulas of a registration, expected result: unique names, known variables that
+can be given a value, and rates among the target currencies
+*/
+func TestValidateFormulas(t *testing.T) {
+	features := utils.Features{"population": true, "targetCurrencies": []string{"eur"}}
+	tests := []struct {
+		name     string
+		formulas []utils.Formula
+		valid    bool
+	}{
+		{"valid", []utils.Formula{{Name: "perKm2", Expression: "population / area"}, {Name: "cents", Expression: "rates.EUR * 100"}}, true},
+		{"duplicate name", []utils.Formula{{Name: "a", Expression: "1"}, {Name: "a", Expression: "2"}}, false},
+		{"invalid name", []utils.Formula{{Name: "per km2", Expression: "population / area"}}, false},
+		{"unknown variable", []utils.Formula{{Name: "a", Expression: "volcanoes * 2"}}, false},
+		{"rate outside the targets", []utils.Formula{{Name: "a", Expression: "rates.USD"}}, false},
+		{"function call", []utils.Formula{{Name: "a", Expression: "os.Exit(1)"}}, false},
+		{"property of a feature", []utils.Formula{{Name: "a", Expression: "temperatureScales.fahrenheit - coordinates.latitude"}}, true},
+		{"rates without a code", []utils.Formula{{Name: "a", Expression: "rates * 2"}}, false},
+		{"property of a number", []utils.Formula{{Name: "a", Expression: "population.foo"}}, false},
+		{"property of a text", []utils.Formula{{Name: "a", Expression: "capital.anything + 1"}}, false},
+	}
+
+	for _, test := range tests {
+		err := ValidateFormulas(test.formulas, features)
+		if test.valid != (err == nil) {
+			t.Errorf("%s: expected valid %t, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+/*
+floatPointer Returns a pointer to a number
+*/
+func floatPointer(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+/*
+ParseFeatureNames Splits a comma separated list of feature names, checking that every feature exists
+*/
+func ParseFeatureNames(list string) ([]string, error) {
+	names := []string{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, exists := GetFeature(name); !exists {
+			return nil, fmt.Errorf("unknown feature '%s'", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+/*
+BuildGeoJSON Builds a GeoJSON FeatureCollection with one Point for every country registration with the coordinates
+feature. A region keeps the countries in that REST Countries region or subregion, and features keep the
+registrations with every one of them enabled, with only those features as properties. At most
+GEOJSON_BUILD_CONCURRENCY dashboards are built at once; a registration that fails is left out, while a cancelled
+request fails as a whole.
+*/
+func BuildGeoJSON(ctx context.Context, regs []utils.Dashboard, region string, features []string) (*utils.GeoJSONFeatureCollection, error) {
+	var selected []utils.Dashboard
+	for _, reg := range regs {
+		if geoJSONCandidate(reg, features) {
+			selected = append(selected, reg)
+		}
+	}
+
+	built := make([]*utils.GeoJSONFeature, len(selected))
+	slots := make(chan struct{}, config.GEOJSON_BUILD_CONCURRENCY)
+	var wg sync.WaitGroup
+	for i := range selected {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			feature, err := buildGeoJSONFeature(ctx, &selected[i], region, features)
+			if err != nil {
+				log.Println("Error building GeoJSON feature of dashboard with id " + selected[i].Id + ": " + err.Error())
+				return
+			}
+			built[i] = feature
+		}()
+	}
+	wg.Wait()
+
+	// The registrations left out after the deadline were not failures of their own, so no upstream is named
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("building the GeoJSON was cut short: %w", ctx.Err())
+	}
+
+	collection := &utils.GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []utils.GeoJSONFeature{}}
+	for _, feature := range built {
+		if feature != nil {
+			collection.Features = append(collection.Features, *feature)
+		}
+	}
+	return collection, nil
+}
+
+/*
+geoJSONCandidate Checks if a registration can have a position: a country registration with the coordinates
+feature, and with every one of the given features enabled
+*/
+func geoJSONCandidate(reg utils.Dashboard, features []string) bool {
+	if reg.Type == utils.RegistrationComparison || reg.Type == utils.RegistrationRegion || !reg.Features.Bool("coordinates") {
+		return false
+	}
+	enabled := EnabledFeatures(reg.Features)
+	for _, name := range features {
+		if !containsFeature(enabled, name) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+buildGeoJSONFeature Builds the dashboard of a registration as a GeoJSON Point, or returns nil if the country
+is outside the region
+*/
+func buildGeoJSONFeature(ctx context.Context, reg *utils.Dashboard, region string, features []string) (*utils.GeoJSONFeature, error) {
+	if region != "" {
+		country, err := clients.GetCountryData(ctx, reg.Country, reg.IsoCode)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(country.Region, region) && !strings.EqualFold(country.Subregion, region) {
+			return nil, nil
+		}
+	}
+
+	// The coordinates are always resolved, as they are the position of the point
+	if len(features) > 0 {
+		narrowed, err := ApplyOverrides(reg, DashboardOverrides{Fields: append(append([]string{}, features...), "coordinates")})
+		if err != nil {
+			return nil, err
+		}
+		reg = narrowed
+	}
+	dashboard, err := BuildDashboard(ctx, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers, err := numericValues(dashboard.Features)
+	if err != nil {
+		return nil, err
+	}
+	latitude, hasLatitude := numbers["coordinates.latitude"]
+	longitude, hasLongitude := numbers["coordinates.longitude"]
+	if !hasLatitude || !hasLongitude {
+		return nil, fmt.Errorf("no coordinates found for %s", reg.Country)
+	}
+
+	properties := map[string]interface{}{
+		"id":            reg.Id,
+		"country":       dashboard.Country,
+		"isoCode":       dashboard.IsoCode,
+		"units":         dashboard.Units,
+		"lastRetrieval": dashboard.LastRetrieval,
+	}
+	for name, value := range dashboard.Features {
+		if name != "coordinates" {
+			properties[name] = value
+		}
+	}
+	if len(dashboard.Formulas) > 0 {
+		properties["formulas"] = dashboard.Formulas
+	}
+
+	return &utils.GeoJSONFeature{
+		Type:       "Feature",
+		Id:         reg.Id,
+		Geometry:   utils.GeoJSONPoint{Type: "Point", Coordinates: []float64{longitude, latitude}},
+		Properties: properties,
+	}, nil
+}
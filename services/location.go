@@ -0,0 +1,63 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"errors"
+	"fmt"
+)
+
+/*
+ValidateWeatherLocation Checks the weather location of a registration
+*/
+func ValidateWeatherLocation(location utils.WeatherLocation) error {
+	switch location.Type {
+	case "", utils.LocationCentroid, utils.LocationCapital:
+		return nil
+	case utils.LocationCoordinates:
+		return validateCoordinates(location.Latitude, location.Longitude)
+	default:
+		return fmt.Errorf("unknown weather location type '%s', expected %s, %s or %s",
+			location.Type, utils.LocationCentroid, utils.LocationCapital, utils.LocationCoordinates)
+	}
+}
+
+/*
+validateCoordinates Checks that a latitude and longitude are within their valid ranges
+*/
+func validateCoordinates(latitude float64, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return fmt.Errorf("latitude %f is outside -90 to 90", latitude)
+	}
+	if longitude < -180 || longitude > 180 {
+		return fmt.Errorf("longitude %f is outside -180 to 180", longitude)
+	}
+	return nil
+}
+
+/*
+resolveWeatherLocation Finds the coordinates of the weather location chosen in a registration. A country
+without capital coordinates falls back to its centroid, which is then reported as the location used.
+*/
+func resolveWeatherLocation(location utils.WeatherLocation, country *utils.CountryResponse) (utils.WeatherLocation, error) {
+	switch location.Type {
+	case utils.LocationCoordinates:
+		return location, nil
+	case utils.LocationCapital:
+		if len(country.CapitalInfo.Latlng) >= 2 {
+			return utils.WeatherLocation{
+				Type:      utils.LocationCapital,
+				Latitude:  country.CapitalInfo.Latlng[0],
+				Longitude: country.CapitalInfo.Latlng[1],
+			}, nil
+		}
+	}
+
+	if len(country.Latlng) < 2 {
+		return utils.WeatherLocation{}, errors.New("no coordinates found for country")
+	}
+	return utils.WeatherLocation{
+		Type:      utils.LocationCentroid,
+		Latitude:  country.Latlng[0],
+		Longitude: country.Latlng[1],
+	}, nil
+}
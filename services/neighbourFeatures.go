@@ -0,0 +1,98 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"sync"
+)
+
+/*
+Neighbour Holds the country data of a bordering country, and its temperature today if it was asked for
+*/
+type Neighbour struct {
+	Code        string // ISO 3166-1 alpha-3 code, as listed in the borders
+	Country     *utils.CountryResponse
+	Temperature *float64
+}
+
+/*
+fetchNeighbours Looks up every bordering country, and its forecast if the temperature is enabled, with at most
+NEIGHBOUR_FETCH_CONCURRENCY lookups at once. A neighbour without a forecast gets no temperature.
+*/
+func fetchNeighbours(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	borders := data.Country.Borders
+	neighbours := make([]Neighbour, len(borders))
+	errs := make([]error, len(borders))
+	slots := make(chan struct{}, config.NEIGHBOUR_FETCH_CONCURRENCY)
+	var wg sync.WaitGroup
+	for i, code := range borders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			country, err := clients.GetCountryData(ctx, "", code)
+			if err != nil {
+				errs[i] = fmt.Errorf("neighbour %s: %w", code, err)
+				return
+			}
+			neighbours[i] = Neighbour{Code: code, Country: country}
+
+			if !reg.Features.OptionBool("neighbours", "temperature") || len(country.Latlng) < 2 {
+				return
+			}
+			forecast, err := clients.GetWeatherDate(ctx, country.Latlng[0], country.Latlng[1], config.DEFAULT_FORECAST_DAYS)
+			if err == nil && len(forecast.Daily) > 0 {
+				neighbours[i].Temperature = roundedOrNil(forecast.Daily[0].Temperature)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	data.Neighbours = neighbours
+	return nil
+}
+
+/*
+init Registers the neighbours feature
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "neighbours",
+		dependencies: []Upstream{UpstreamNeighbours},
+		schema: FeatureSchema{
+			Type:        SchemaObject,
+			Description: "Name, capital and population of every bordering country",
+			Properties: map[string]FeatureSchema{
+				"enabled":     {Type: SchemaBoolean, Description: "Switches the feature on"},
+				"temperature": {Type: SchemaBoolean, Description: "Adds the mean forecast temperature of today in °C"},
+			},
+		},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			summaries := []map[string]interface{}{}
+			for _, neighbour := range data.Neighbours {
+				name, _ := translatedNames(neighbour.Country, reg.Display.Lang)
+				summary := map[string]interface{}{
+					"isoCode":    neighbour.Code,
+					"name":       name,
+					"capital":    neighbour.Country.Capital,
+					"population": neighbour.Country.Population,
+				}
+				if reg.Features.OptionBool("neighbours", "temperature") {
+					summary["temperature"] = neighbour.Temperature
+				}
+				summaries = append(summaries, summary)
+			}
+			return summaries, nil
+		},
+	})
+}
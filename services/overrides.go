@@ -0,0 +1,146 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// currenciesField is the feature the currencies of a request are added to
+const currenciesField = "targetCurrencies"
+
+// formulaField is the prefix that selects a formula by name in the fields of a request, such as formulas.perCapita
+const formulaField = "formulas."
+
+/*
+DashboardOverrides Changes to a registration for a single request: the fields to keep, target currencies
+to add, and display settings that replace the ones set. Empty overrides leave the registration as it is.
+*/
+type DashboardOverrides struct {
+	Fields     []string
+	Currencies []string
+	Display    utils.DisplaySetting
+}
+
+/*
+ApplyOverrides Returns a copy of a registration with the overrides of a request applied, leaving the
+registration itself untouched. Display settings replace the ones of the registration. Fields narrow the
+enabled features and formulas to the ones listed, so the upstreams of every other feature are never fetched;
+added currencies are appended to the target currencies, which the fields then have to include.
+*/
+func ApplyOverrides(reg *utils.Dashboard, overrides DashboardOverrides) (*utils.Dashboard, error) {
+	if err := ValidateDisplaySetting(overrides.Display); err != nil {
+		return nil, err
+	}
+	narrowed := *reg
+	if overrides.Display.Units != "" {
+		narrowed.Display.Units = overrides.Display.Units
+	}
+	if overrides.Display.Locale != "" {
+		narrowed.Display.Locale = overrides.Display.Locale
+	}
+	if overrides.Display.Lang != "" {
+		narrowed.Display.Lang = overrides.Display.Lang
+	}
+
+	if len(overrides.Fields) == 0 && len(overrides.Currencies) == 0 {
+		return &narrowed, nil
+	}
+	if reg.Type == utils.RegistrationRegion {
+		return nil, errors.New("fields and currencies can not be used with a region dashboard")
+	}
+	// Added currencies would switch the target currencies back on when the fields leave them out
+	if len(overrides.Fields) > 0 && len(overrides.Currencies) > 0 && !containsName(overrides.Fields, currenciesField) {
+		return nil, fmt.Errorf("currencies can only be added when the fields include %s", currenciesField)
+	}
+
+	if len(overrides.Fields) > 0 {
+		features, formulas, err := narrowFields(reg, overrides.Fields)
+		if err != nil {
+			return nil, err
+		}
+		narrowed.Features = features
+		narrowed.Formulas = formulas
+	}
+
+	if len(overrides.Currencies) == 0 {
+		return &narrowed, nil
+	}
+	// A new slice, so the target currencies of the registration are never appended to
+	targets := append([]string{}, narrowed.Features.Strings(currenciesField)...)
+	for _, code := range overrides.Currencies {
+		if !containsCode(targets, code) {
+			targets = append(targets, code)
+		}
+	}
+	// A new map, so the features of the registration are never changed
+	features := utils.Features{}
+	for name, value := range narrowed.Features {
+		features[name] = value
+	}
+	features[currenciesField] = targets
+	narrowed.Features = features
+	return &narrowed, nil
+}
+
+/*
+narrowFields Keeps the features and formulas of a registration that are listed in the fields. A field is a
+feature name or formulas.<name>; a feature listed but not enabled in the registration stays disabled.
+*/
+func narrowFields(reg *utils.Dashboard, fields []string) (utils.Features, []utils.Formula, error) {
+	features := make(map[string]bool)
+	formulas := make(map[string]bool)
+	for _, field := range fields {
+		if name, isFormula := strings.CutPrefix(field, formulaField); isFormula {
+			if !hasFormula(reg.Formulas, name) {
+				return nil, nil, fmt.Errorf("unknown formula '%s'", name)
+			}
+			formulas[name] = true
+			continue
+		}
+		if _, exists := GetFeature(field); !exists {
+			return nil, nil, fmt.Errorf("unknown field '%s'", field)
+		}
+		features[field] = true
+	}
+
+	narrowed := utils.Features{}
+	for name, value := range reg.Features {
+		if features[name] {
+			narrowed[name] = value
+		}
+	}
+
+	kept := []utils.Formula{}
+	for _, formula := range reg.Formulas {
+		if formulas[formula.Name] {
+			kept = append(kept, formula)
+		}
+	}
+	return narrowed, kept, nil
+}
+
+/*
+hasFormula Checks if a registration has a formula with the given name
+*/
+func hasFormula(formulas []utils.Formula, name string) bool {
+	for _, formula := range formulas {
+		if formula.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+containsCode Checks if a currency code is in a list, ignoring case
+*/
+func containsCode(codes []string, code string) bool {
+	for _, existing := range codes {
+		if strings.EqualFold(existing, code) {
+			return true
+		}
+	}
+	return false
+}
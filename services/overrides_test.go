@@ -0,0 +1,74 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"testing"
+)
+
+/*
+TestApplyOverrides tests the per-request overrides of a registration, expected result: fields narrow the features
+and formulas, currencies are added, display settings are replaced and the registration itself is never changed
+*/
+func TestApplyOverrides(t *testing.T) {
+	reg := &utils.Dashboard{
+		Country:  "Norway",
+		Features: utils.Features{"capital": true, "population": true, "targetCurrencies": []string{"EUR"}},
+		Formulas: []utils.Formula{{Name: "perMille", Expression: "population / 1000"}},
+		Display:  utils.DisplaySetting{Units: utils.UnitsMetric, Locale: "en-GB"},
+	}
+
+	overridden, err := ApplyOverrides(reg, DashboardOverrides{
+		Fields:  []string{"population", "targetCurrencies", "formulas.perMille"},
+		Display: utils.DisplaySetting{Units: utils.UnitsImperial},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if overridden.Features.Bool("capital") || !overridden.Features.Bool("population") {
+		t.Errorf("Expected only the population and currencies to be kept, got %v", overridden.Features)
+	}
+	if len(overridden.Formulas) != 1 {
+		t.Errorf("Expected the formula to be kept, got %v", overridden.Formulas)
+	}
+	if overridden.Display.Units != utils.UnitsImperial || overridden.Display.Locale != "en-GB" {
+		t.Errorf("Expected imperial units and the registered locale, got %+v", overridden.Display)
+	}
+
+	overridden, err = ApplyOverrides(reg, DashboardOverrides{Currencies: []string{"usd", "eur"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if targets := overridden.Features.Strings("targetCurrencies"); len(targets) != 2 || targets[1] != "usd" {
+		t.Errorf("Expected EUR and usd, got %v", targets)
+	}
+
+	if !reg.Features.Bool("capital") || len(reg.Features.Strings("targetCurrencies")) != 1 || reg.Display.Units != utils.UnitsMetric {
+		t.Errorf("Expected the registration to be unchanged, got %+v", reg)
+	}
+}
+
+/*
+TestApplyOverridesInvalid tests overrides that can not be applied, expected result: an error for each
+*/
+func TestApplyOverridesInvalid(t *testing.T) {
+	country := &utils.Dashboard{Features: utils.Features{"capital": true}}
+	region := &utils.Dashboard{Type: utils.RegistrationRegion}
+	tests := []struct {
+		name      string
+		reg       *utils.Dashboard
+		overrides DashboardOverrides
+	}{
+		{"unknown field", country, DashboardOverrides{Fields: []string{"volcanoes"}}},
+		{"unknown formula", country, DashboardOverrides{Fields: []string{"formulas.missing"}}},
+		{"unsupported units", country, DashboardOverrides{Display: utils.DisplaySetting{Units: "kelvin"}}},
+		{"fields of a region", region, DashboardOverrides{Fields: []string{"capital"}}},
+		{"currencies of a region", region, DashboardOverrides{Currencies: []string{"EUR"}}},
+		{"currencies outside the fields", country, DashboardOverrides{Fields: []string{"capital"}, Currencies: []string{"USD"}}},
+	}
+
+	for _, test := range tests {
+		if _, err := ApplyOverrides(test.reg, test.overrides); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
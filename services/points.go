@@ -0,0 +1,106 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/*
+ValidatePoints Checks the named points of a registration: every point needs a unique label and
+valid coordinates
+*/
+func ValidatePoints(points []utils.NamedPoint) error {
+	if len(points) > config.MAX_POINTS {
+		return fmt.Errorf("at most %d points are allowed, got %d", config.MAX_POINTS, len(points))
+	}
+
+	labels := make(map[string]bool)
+	for i, point := range points {
+		label := strings.TrimSpace(point.Label)
+		if label == "" {
+			return fmt.Errorf("point %d has no label", i)
+		}
+		if labels[strings.ToLower(label)] {
+			return fmt.Errorf("label '%s' is used by more than one point", label)
+		}
+		labels[strings.ToLower(label)] = true
+
+		if err := validateCoordinates(point.Latitude, point.Longitude); err != nil {
+			return fmt.Errorf("point '%s': %w", label, err)
+		}
+	}
+	return nil
+}
+
+/*
+isWeatherFeature Checks if a feature is computed from the weather alone, so it can be resolved for a named point
+*/
+func isWeatherFeature(provider FeatureProvider) bool {
+	dependencies := provider.Dependencies()
+	for _, upstream := range dependencies {
+		if upstream != UpstreamWeather {
+			return false
+		}
+	}
+	return len(dependencies) > 0
+}
+
+/*
+fetchPoints Gets the weather forecast of every named point concurrently. Each point is cached under its own
+coordinates by the weather client.
+*/
+func fetchPoints(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	forecasts := make([]*utils.WeatherForecast, len(reg.Points))
+	errs := make([]error, len(reg.Points))
+
+	var wg sync.WaitGroup
+	for i, point := range reg.Points {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			forecasts[i], errs[i] = clients.GetWeatherDate(ctx, point.Latitude, point.Longitude, weatherHorizon(reg.Features))
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("point '%s': %w", reg.Points[i].Label, err)
+		}
+	}
+	data.Points = forecasts
+	return nil
+}
+
+/*
+resolvePoints Resolves the enabled weather features for every named point, in the order of the registration
+*/
+func resolvePoints(ctx context.Context, reg *utils.Dashboard, providers []FeatureProvider, data *UpstreamData) ([]utils.PointDashboard, error) {
+	points := []utils.PointDashboard{}
+	for i, point := range reg.Points {
+		pointData := &UpstreamData{
+			Country:         data.Country,
+			Weather:         data.Points[i],
+			WeatherLocation: utils.WeatherLocation{Type: utils.LocationCoordinates, Latitude: point.Latitude, Longitude: point.Longitude},
+		}
+
+		features := make(map[string]interface{})
+		for _, provider := range providers {
+			if !isWeatherFeature(provider) {
+				continue
+			}
+			value, err := provider.Resolve(ctx, reg, pointData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve feature %s for point '%s': %w", provider.Name(), point.Label, err)
+			}
+			features[provider.Name()] = value
+		}
+		points = append(points, utils.PointDashboard{NamedPoint: point, Features: features})
+	}
+	return points, nil
+}
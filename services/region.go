@@ -0,0 +1,238 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+validateRegion Checks the region of a region registration: exactly one of region, subregion and isoCodes is set
+*/
+func validateRegion(region utils.RegionSetting) error {
+	set := 0
+	for _, defined := range []bool{region.Region != "", region.Subregion != "", len(region.IsoCodes) > 0} {
+		if defined {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("a region needs exactly one of region, subregion and isoCodes")
+	}
+
+	if len(region.IsoCodes) > config.MAX_REGION_COUNTRIES {
+		return fmt.Errorf("at most %d isoCodes are allowed, got %d", config.MAX_REGION_COUNTRIES, len(region.IsoCodes))
+	}
+	codes := make(map[string]bool)
+	for _, code := range region.IsoCodes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			return errors.New("isoCodes can not contain an empty code")
+		}
+		if codes[code] {
+			return fmt.Errorf("isoCode '%s' is listed more than once", code)
+		}
+		codes[code] = true
+	}
+	return nil
+}
+
+/*
+regionName Returns the display name of a region, falling back to the region or subregion queried
+*/
+func regionName(region utils.RegionSetting) string {
+	switch {
+	case region.Name != "":
+		return region.Name
+	case region.Region != "":
+		return region.Region
+	case region.Subregion != "":
+		return region.Subregion
+	default:
+		return strings.Join(region.IsoCodes, ",")
+	}
+}
+
+/*
+fetchRegionCountries Gets the countries of a region, either by a region or subregion query, or by looking up
+every ISO code with at most REGION_FETCH_CONCURRENCY lookups at once
+*/
+func fetchRegionCountries(ctx context.Context, region utils.RegionSetting) ([]utils.CountryResponse, error) {
+	switch {
+	case region.Region != "":
+		return clients.GetRegionCountries(ctx, "region", region.Region)
+	case region.Subregion != "":
+		return clients.GetRegionCountries(ctx, "subregion", region.Subregion)
+	}
+
+	countries := make([]utils.CountryResponse, len(region.IsoCodes))
+	errs := make([]error, len(region.IsoCodes))
+	slots := make(chan struct{}, config.REGION_FETCH_CONCURRENCY)
+	var wg sync.WaitGroup
+	for i, code := range region.IsoCodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			country, err := clients.GetCountryData(ctx, "", strings.ToUpper(strings.TrimSpace(code)))
+			if err != nil {
+				errs[i] = fmt.Errorf("country '%s': %w", code, err)
+				return
+			}
+			countries[i] = *country
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+/*
+fetchRegionTemperatures Gets the mean forecast temperature at the centre of every country, with at most
+REGION_FETCH_CONCURRENCY forecasts at once. A country without coordinates or forecast gets nil, so one
+failing forecast does not hide the region; a cancelled request fails as a whole.
+*/
+func fetchRegionTemperatures(ctx context.Context, countries []utils.CountryResponse) ([]*float64, error) {
+	temperatures := make([]*float64, len(countries))
+	slots := make(chan struct{}, config.REGION_FETCH_CONCURRENCY)
+	var wg sync.WaitGroup
+	for i, country := range countries {
+		if len(country.Latlng) < 2 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			forecast, err := clients.GetWeatherDate(ctx, country.Latlng[0], country.Latlng[1], config.DEFAULT_FORECAST_DAYS)
+			if err != nil {
+				return
+			}
+			values := forecast.FirstDays(config.DEFAULT_FORECAST_DAYS).Temperatures()
+			if len(values) > 0 {
+				mean := clients.Average(values)
+				temperatures[i] = &mean
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, &UpstreamError{Upstream: UpstreamWeather, Err: ctx.Err()}
+	}
+	return temperatures, nil
+}
+
+/*
+BuildRegion Populates a region dashboard with the total population and area, the population-weighted mean
+temperature and the currencies of its countries. With drillDown, the details of every country are included.
+*/
+func BuildRegion(ctx context.Context, reg *utils.Dashboard, drillDown bool) (*utils.RegionDashboard, error) {
+	countries, err := fetchRegionCountries(ctx, reg.Region)
+	if err != nil {
+		return nil, &UpstreamError{Upstream: UpstreamCountry, Err: err}
+	}
+	temperatures, err := fetchRegionTemperatures(ctx, countries)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &utils.RegionDashboard{
+		Type:          utils.RegistrationRegion,
+		Name:          regionName(reg.Region),
+		CountryCount:  len(countries),
+		Units:         displayUnits(reg.Display),
+		LastRetrieval: time.Now().Local().String(),
+	}
+
+	currencies := make(map[string]bool)
+	var weightedSum, weight float64
+	for i, country := range countries {
+		dashboard.Aggregates.TotalPopulation += country.Population
+		dashboard.Aggregates.TotalArea += country.Area
+
+		codes := []string{}
+		for code := range country.Currencies {
+			codes = append(codes, code)
+			currencies[code] = true
+		}
+		sort.Strings(codes)
+
+		if temperatures[i] != nil {
+			weightedSum += *temperatures[i] * float64(country.Population)
+			weight += float64(country.Population)
+		}
+
+		if drillDown {
+			name, _ := translatedNames(&country, reg.Display.Lang)
+			dashboard.Countries = append(dashboard.Countries, utils.RegionCountry{
+				Country:     name,
+				IsoCode:     country.Cca2,
+				Population:  country.Population,
+				Area:        country.Area,
+				Temperature: temperatures[i],
+				Currencies:  codes,
+			})
+		}
+	}
+
+	// The weighted mean is unknown when no country with inhabitants has a forecast
+	if weight > 0 {
+		mean := math.Round(weightedSum/weight*100) / 100
+		dashboard.Aggregates.MeanTemperature = &mean
+	}
+
+	dashboard.Aggregates.Currencies = []string{}
+	for code := range currencies {
+		dashboard.Aggregates.Currencies = append(dashboard.Aggregates.Currencies, code)
+	}
+	sort.Strings(dashboard.Aggregates.Currencies)
+
+	if drillDown {
+		sort.Slice(dashboard.Countries, func(i, j int) bool {
+			return dashboard.Countries[i].Country < dashboard.Countries[j].Country
+		})
+	}
+	if err := applyRegionDisplay(dashboard, reg.Display); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+/*
+applyRegionDisplay Converts the aggregates and countries of a region dashboard into its unit system, and adds
+the display strings of the aggregates if a locale is set
+*/
+func applyRegionDisplay(dashboard *utils.RegionDashboard, setting utils.DisplaySetting) error {
+	if dashboard.Units == utils.UnitsImperial {
+		var converted utils.RegionDashboard
+		if err := convertUnits(dashboard, regionQuantities, dashboard.Units, &converted); err != nil {
+			return err
+		}
+		*dashboard = converted
+	}
+	if setting.Locale != "" {
+		formatted, err := formatValues(map[string]interface{}{"aggregates": dashboard.Aggregates},
+			regionQuantities, dashboard.Units, setting.Locale)
+		if err != nil {
+			return err
+		}
+		dashboard.Formatted = formatted
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+parseUTCOffset Parses a REST Countries time zone, such as UTC+05:30 or UTC, into seconds east of UTC
+*/
+func parseUTCOffset(timezone string) (int, error) {
+	offset, found := strings.CutPrefix(timezone, "UTC")
+	if !found {
+		return 0, fmt.Errorf("unknown time zone %s", timezone)
+	}
+	if offset == "" {
+		return 0, nil
+	}
+
+	sign := 1
+	switch offset[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return 0, fmt.Errorf("unknown time zone %s", timezone)
+	}
+	hours, minutes, _ := strings.Cut(offset[1:], ":")
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("unknown time zone %s", timezone)
+	}
+	m := 0
+	if minutes != "" {
+		if m, err = strconv.Atoi(minutes); err != nil {
+			return 0, fmt.Errorf("unknown time zone %s", timezone)
+		}
+	}
+	return sign * (h*3600 + m*60), nil
+}
+
+/*
+standardOffset Returns the offset of a zone outside daylight saving time, the smaller of its offsets in
+January and July, in seconds east of UTC
+*/
+func standardOffset(location *time.Location, year int) int {
+	_, january := time.Date(year, time.January, 1, 0, 0, 0, 0, location).Zone()
+	_, july := time.Date(year, time.July, 1, 0, 0, 0, 0, location).Zone()
+	return min(january, july)
+}
+
+/*
+zoneForOffset Finds the first zone of a country whose standard offset is the given offset. Without such a
+zone, a fixed zone is returned, which never observes DST.
+*/
+func zoneForOffset(cca2 string, timezone string, offset int, now time.Time) *time.Location {
+	for _, name := range utils.ZonesOfCountry(cca2) {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+		if standardOffset(location, now.Year()) == offset {
+			return location
+		}
+	}
+	return time.FixedZone(timezone, offset)
+}
+
+/*
+formatOffset Formats an offset in seconds as +hh:mm
+*/
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+/*
+localTimes Returns the current local time in every time zone of a country
+*/
+func localTimes(country *utils.CountryResponse, now time.Time) ([]map[string]interface{}, error) {
+	times := []map[string]interface{}{}
+	for _, timezone := range country.Timezones {
+		offset, err := parseUTCOffset(timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		location := zoneForOffset(country.Cca2, timezone, offset, now)
+		local := now.In(location)
+		abbreviation, currentOffset := local.Zone()
+		times = append(times, map[string]interface{}{
+			"timezone":     timezone,
+			"zone":         location.String(),
+			"localTime":    local.Format(time.RFC3339),
+			"utcOffset":    formatOffset(currentOffset),
+			"abbreviation": abbreviation,
+			"dst":          local.IsDST(),
+		})
+	}
+	return times, nil
+}
+
+/*
+init Registers the local time feature
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "localTime",
+		dependencies: []Upstream{UpstreamCountry},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Current local time and UTC offset in every time zone of the country"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			return localTimes(data.Country, time.Now())
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+/*
+TestZoneForOffset tests the zone chosen for a REST Countries time zone, expected result: the first zone of the
+country with that standard offset from the embedded zone table, or a fixed zone when the country has none
+*/
+func TestZoneForOffset(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cca2     string
+		timezone string
+		expected string
+	}{
+		{"NO", "UTC+01:00", "Europe/Oslo"},
+		{"no", "UTC+01:00", "Europe/Oslo"},
+		{"US", "UTC-05:00", "America/New_York"},
+		{"JP", "UTC+09:00", "Asia/Tokyo"},
+		{"IN", "UTC+05:30", "Asia/Kolkata"},
+		{"NO", "UTC+03:00", "UTC+03:00"},
+		{"XX", "UTC", "UTC"},
+	}
+
+	for _, test := range tests {
+		offset, err := parseUTCOffset(test.timezone)
+		if err != nil {
+			t.Fatalf("%s: %v", test.timezone, err)
+		}
+		if zone := zoneForOffset(test.cca2, test.timezone, offset, now).String(); zone != test.expected {
+			t.Errorf("%s %s: expected %s, got %s", test.cca2, test.timezone, test.expected, zone)
+		}
+	}
+}
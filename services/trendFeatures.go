@@ -0,0 +1,107 @@
+package services
+
+import (
+	"assignment-2/database"
+	"assignment-2/utils"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// trendPeriods are the periods in days the currency trend compares the current rates with
+var trendPeriods = []int{1, 7, 30}
+
+/*
+fetchRateHistory Gets the stored history of every fetched rate concurrently, from the start of the UTC day of the
+longest trend period
+*/
+func fetchRateHistory(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	since := time.Now().UTC().AddDate(0, 0, -trendPeriods[len(trendPeriods)-1]).Truncate(24 * time.Hour)
+
+	type pair struct{ base, target string }
+	var pairs []pair
+	for _, group := range data.Currency {
+		for _, rate := range group.Rates {
+			pairs = append(pairs, pair{base: group.BaseCode, target: rate.Code})
+		}
+	}
+
+	histories := make([][]utils.RatePoint, len(pairs))
+	errs := make([]error, len(pairs))
+	var wg sync.WaitGroup
+	for i, p := range pairs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			histories[i], errs[i] = database.GetRateHistory(ctx, p.base, p.target, since)
+		}()
+	}
+	wg.Wait()
+
+	history := make(map[string][]utils.RatePoint)
+	for i, p := range pairs {
+		if errs[i] != nil {
+			return fmt.Errorf("history of %s_%s: %w", p.base, p.target, errs[i])
+		}
+		history[p.base+"_"+p.target] = histories[i]
+	}
+	data.RateHistory = history
+	return nil
+}
+
+/*
+rateChange Compares a rate with the latest stored rate of the last day at least the given number of days ago, or
+returns nil if the history does not go back that far. A day can hold a rate of every fetch, so its latest is used.
+*/
+func rateChange(rate float64, history []utils.RatePoint, days int, now time.Time) interface{} {
+	cutoff := now.UTC().AddDate(0, 0, -days).Format(time.DateOnly)
+
+	// The history is sorted oldest first, so the last match is the latest rate of the day closest to the cutoff
+	var past *utils.RatePoint
+	for i := range history {
+		if history[i].Date <= cutoff {
+			past = &history[i]
+		}
+	}
+	if past == nil || past.Rate == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"date":    past.Date,
+		"rate":    past.Rate,
+		"change":  roundTo(rate-past.Rate, 6),
+		"percent": roundTo((rate-past.Rate)/past.Rate*100, 2),
+	}
+}
+
+/*
+init Registers the currency trend feature
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "currencyTrend",
+		dependencies: []Upstream{UpstreamCurrency, UpstreamRateHistory},
+		schema:       FeatureSchema{Type: SchemaBoolean, Description: "Change of the target currency rates over 1, 7 and 30 days"},
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			now := time.Now()
+			trends := []map[string]interface{}{}
+			for _, group := range data.Currency {
+				rates := make(map[string]interface{})
+				for _, rate := range group.Rates {
+					history := data.RateHistory[group.BaseCode+"_"+rate.Code]
+					trend := map[string]interface{}{"rate": rate.Rate}
+					for _, days := range trendPeriods {
+						trend[fmt.Sprintf("%dd", days)] = rateChange(rate.Rate, history, days, now)
+					}
+					rates[rate.Code] = trend
+				}
+				trends = append(trends, map[string]interface{}{
+					"baseCode": group.BaseCode,
+					"rates":    rates,
+				})
+			}
+			return trends, nil
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"testing"
+	"time"
+)
+
+/*
+TestRateChange tests the change of a rate over a period, expected result: the change from the latest rate of the
+last day at least that many days ago, and nil when the history does not go back that far
+*/
+func TestRateChange(t *testing.T) {
+	now := time.Date(2025, time.April, 30, 15, 0, 0, 0, time.UTC)
+	point := func(date string, hour int, rate float64) utils.RatePoint {
+		timestamp, _ := time.Parse(time.DateOnly, date)
+		return utils.RatePoint{Date: date, Rate: rate, Timestamp: timestamp.Add(time.Duration(hour) * time.Hour)}
+	}
+	// Several fetches a day, oldest first as stored
+	history := []utils.RatePoint{
+		point("2025-04-22", 8, 0.080),
+		point("2025-04-23", 8, 0.081),
+		point("2025-04-23", 20, 0.082),
+		point("2025-04-29", 6, 0.090),
+		point("2025-04-29", 23, 0.100),
+		point("2025-04-30", 9, 0.110),
+	}
+
+	tests := []struct {
+		days     int
+		date     string
+		past     float64
+		expected bool
+	}{
+		{days: 1, date: "2025-04-29", past: 0.100, expected: true},
+		{days: 7, date: "2025-04-23", past: 0.082, expected: true},
+		{days: 30},
+	}
+	for _, test := range tests {
+		change := rateChange(0.11, history, test.days, now)
+		if !test.expected {
+			if change != nil {
+				t.Errorf("%dd: expected nil, got %v", test.days, change)
+			}
+			continue
+		}
+		got, ok := change.(map[string]interface{})
+		if !ok || got["date"] != test.date || got["rate"] != test.past {
+			t.Errorf("%dd: expected the rate %v of %s, got %v", test.days, test.past, test.date, change)
+		}
+	}
+}
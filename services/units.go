@@ -0,0 +1,148 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"encoding/json"
+	"math"
+)
+
+/*
+quantity The unit of a dashboard value in both unit systems, with the conversion from metric to imperial.
+Values without a conversion are the same in both systems.
+*/
+type quantity struct {
+	metric   string
+	imperial string
+	convert  func(value float64) float64
+}
+
+// The quantities of the dashboard values
+var (
+	temperatureQuantity = quantity{"°C", "°F", func(value float64) float64 { return value*9/5 + 32 }}
+	// A difference of temperatures, such as an anomaly, has no offset
+	temperatureDifferenceQuantity = quantity{"°C", "°F", func(value float64) float64 { return value * 9 / 5 }}
+	precipitationQuantity         = quantity{"mm", "in", func(value float64) float64 { return value / 25.4 }}
+	speedQuantity                 = quantity{"km/h", "mph", func(value float64) float64 { return value / 1.609344 }}
+	areaQuantity                  = quantity{"km²", "mi²", func(value float64) float64 { return value / 2.589988110336 }}
+	densityQuantity               = quantity{"/km²", "/mi²", func(value float64) float64 { return value * 2.589988110336 }}
+	percentQuantity               = quantity{"%", "%", nil}
+	concentrationQuantity         = quantity{"μg/m³", "μg/m³", nil}
+)
+
+// dashboardQuantities holds the quantity of every feature value with a unit by its dotted path. The items
+// of a list share the path of the list, so dailyForecast.days.temperature is the temperature of every day.
+var dashboardQuantities = map[string]quantity{
+	"area":                                        areaQuantity,
+	"temperature":                                 temperatureQuantity,
+	"precipitation":                               percentQuantity,
+	"populationDensity":                           densityQuantity,
+	"worldPopulationShare":                        percentQuantity,
+	"dailyForecast.days.temperature":              temperatureQuantity,
+	"dailyForecast.days.temperatureMin":           temperatureQuantity,
+	"dailyForecast.days.temperatureMax":           temperatureQuantity,
+	"dailyForecast.days.precipitationSum":         precipitationQuantity,
+	"dailyForecast.days.precipitationProbability": percentQuantity,
+	"dailyForecast.days.windSpeedMax":             speedQuantity,
+	"dailyForecast.days.humidity":                 percentQuantity,
+	"temperatureRange.min":                        temperatureQuantity,
+	"temperatureRange.max":                        temperatureQuantity,
+	"temperatureRange.days.min":                   temperatureQuantity,
+	"temperatureRange.days.max":                   temperatureQuantity,
+	"precipitationSum.total":                      precipitationQuantity,
+	"precipitationSum.days.value":                 precipitationQuantity,
+	"wind.speedMax":                               speedQuantity,
+	"wind.days.speedMax":                          speedQuantity,
+	"humidity.mean":                               percentQuantity,
+	"humidity.days.value":                         percentQuantity,
+	"climateComparison.forecastMean":              temperatureQuantity,
+	"climateComparison.historicalMean":            temperatureQuantity,
+	"climateComparison.anomaly":                   temperatureDifferenceQuantity,
+	"climateComparison.anomalyPercent":            percentQuantity,
+	"airQuality.pm2_5":                            concentrationQuantity,
+	"airQuality.pm10":                             concentrationQuantity,
+	"airQuality.ozone":                            concentrationQuantity,
+	"neighbours.temperature":                      temperatureQuantity,
+}
+
+// regionQuantities holds the quantity of every region dashboard value with a unit by its dotted path
+var regionQuantities = map[string]quantity{
+	"aggregates.totalArea":       areaQuantity,
+	"aggregates.meanTemperature": temperatureQuantity,
+	"countries.area":             areaQuantity,
+	"countries.temperature":      temperatureQuantity,
+}
+
+/*
+displayUnits Returns the unit system of a dashboard, metric unless imperial is set
+*/
+func displayUnits(setting utils.DisplaySetting) string {
+	if setting.Units == utils.UnitsImperial {
+		return utils.UnitsImperial
+	}
+	return utils.UnitsMetric
+}
+
+/*
+symbol Returns the unit symbol of a quantity in a unit system
+*/
+func (q quantity) symbol(units string) string {
+	if units == utils.UnitsImperial {
+		return q.imperial
+	}
+	return q.metric
+}
+
+/*
+convertUnits Converts the metric values with a unit into the given unit system and decodes the result into
+result. Values are resolved in metric, so the metric system leaves them as they are; converted values are
+rounded to 2 decimals.
+*/
+func convertUnits(values interface{}, quantities map[string]quantity, units string, result interface{}) error {
+	// A JSON round trip turns every value into plain maps, lists and float64 numbers
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if units != utils.UnitsImperial {
+		return json.Unmarshal(encoded, result)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return err
+	}
+
+	var convert func(path string, value interface{}) interface{}
+	convert = func(path string, value interface{}) interface{} {
+		switch v := value.(type) {
+		case float64:
+			if q, exists := quantities[path]; exists && q.convert != nil {
+				return math.Round(q.convert(v)*100) / 100
+			}
+		case map[string]interface{}:
+			for key, nested := range v {
+				v[key] = convert(joinPath(path, key), nested)
+			}
+		case []interface{}:
+			for i, item := range v {
+				v[i] = convert(path, item)
+			}
+		}
+		return value
+	}
+
+	encoded, err = json.Marshal(convert("", decoded))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, result)
+}
+
+/*
+joinPath Appends a key to a dotted path
+*/
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"assignment-2/utils"
+	"testing"
+)
+
+/*
+TestConvertUnits tests the conversion of metric values into a unit system, expected result: imperial converts
+every value with a unit, including the items of lists, and metric leaves the values as they are
+*/
+func TestConvertUnits(t *testing.T) {
+	values := map[string]interface{}{
+		"area":        1000.0,
+		"temperature": 20.0,
+		"population":  5379475,
+		"precipitationSum": map[string]interface{}{
+			"total": 25.4,
+			"days":  []interface{}{map[string]interface{}{"date": "2025-04-07", "value": 12.7}},
+		},
+		"climateComparison": map[string]interface{}{"anomaly": -1.5},
+	}
+	tests := []struct {
+		units    string
+		expected map[string]float64
+	}{
+		{utils.UnitsImperial, map[string]float64{
+			"area": 386.1, "temperature": 68, "population": 5379475, "precipitationSum.total": 1,
+			"precipitationSum.days.value": 0.5, "climateComparison.anomaly": -2.7,
+		}},
+		{utils.UnitsMetric, map[string]float64{
+			"area": 1000, "temperature": 20, "population": 5379475, "precipitationSum.total": 25.4,
+			"precipitationSum.days.value": 12.7, "climateComparison.anomaly": -1.5,
+		}},
+	}
+
+	for _, test := range tests {
+		var converted map[string]interface{}
+		if err := convertUnits(values, dashboardQuantities, test.units, &converted); err != nil {
+			t.Fatalf("%s: %v", test.units, err)
+		}
+		days := converted["precipitationSum"].(map[string]interface{})["days"].([]interface{})
+		got := map[string]float64{
+			"area":                        converted["area"].(float64),
+			"temperature":                 converted["temperature"].(float64),
+			"population":                  converted["population"].(float64),
+			"precipitationSum.total":      converted["precipitationSum"].(map[string]interface{})["total"].(float64),
+			"precipitationSum.days.value": days[0].(map[string]interface{})["value"].(float64),
+			"climateComparison.anomaly":   converted["climateComparison"].(map[string]interface{})["anomaly"].(float64),
+		}
+		for path, value := range test.expected {
+			if got[path] != value {
+				t.Errorf("%s: expected %s to be %v, got %v", test.units, path, value, got[path])
+			}
+		}
+	}
+}
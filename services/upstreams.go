@@ -0,0 +1,297 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+/*
+Upstream Names an external data source that dashboard features depend on
+*/
+type Upstream string
+
+// The upstream data sources of the dashboards
+const (
+	UpstreamCountry  Upstream = "country"
+	UpstreamWeather  Upstream = "weather"
+	UpstreamCurrency Upstream = "currency"
+	// UpstreamPoints is the weather at the named points of a registration
+	UpstreamPoints Upstream = "points"
+	// UpstreamClimate is the historical weather of the calendar days of the forecast
+	UpstreamClimate Upstream = "climate"
+	// UpstreamAirQuality is the current air quality at the weather location
+	UpstreamAirQuality Upstream = "airQuality"
+	// UpstreamReferenceRates is the rates from the country currencies to the reference currencies
+	UpstreamReferenceRates Upstream = "referenceRates"
+	// UpstreamRateHistory is the stored history of the target currency rates
+	UpstreamRateHistory Upstream = "rateHistory"
+	// UpstreamNeighbours is the country data of the bordering countries
+	UpstreamNeighbours Upstream = "neighbours"
+)
+
+/*
+UpstreamData Holds the upstream data fetched for one dashboard. Each upstream writes only its own field.
+*/
+type UpstreamData struct {
+	Country         *utils.CountryResponse
+	Weather         *utils.WeatherForecast
+	WeatherLocation utils.WeatherLocation // Where the weather was looked up
+	Currency        []utils.GroupedCurrencyResponse
+	Points          []*utils.WeatherForecast // The weather of each named point, in registration order
+	Climate         *utils.ClimateNormal
+	AirQuality      *utils.AirQuality
+	ReferenceRates  map[string]map[string]float64 // Rates to the reference currencies, by base currency
+	RateHistory     map[string][]utils.RatePoint  // Daily rates, by base/target pair such as NOK_EUR
+	Neighbours      []Neighbour                   // The bordering countries, in the order of the borders
+}
+
+/*
+UpstreamError Reports which upstream failed while fetching the data for a dashboard
+*/
+type UpstreamError struct {
+	Upstream Upstream
+	Err      error
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("failed to fetch %s data: %s", e.Upstream, e.Err.Error())
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+/*
+upstreamSource Describes how an upstream is fetched and which other upstreams it needs first
+*/
+type upstreamSource struct {
+	requires []Upstream
+	fetch    func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error
+}
+
+// upstreamSources holds the fetch function of every upstream
+var upstreamSources = map[Upstream]upstreamSource{
+	UpstreamCountry: {
+		fetch: fetchCountry,
+	},
+	UpstreamWeather: {
+		// The weather location is found through the country data
+		requires: []Upstream{UpstreamCountry},
+		fetch:    fetchWeather,
+	},
+	UpstreamCurrency: {
+		// The base currencies are the currencies of the country
+		requires: []Upstream{UpstreamCountry},
+		fetch:    fetchCurrency,
+	},
+	UpstreamPoints: {
+		fetch: fetchPoints,
+	},
+	UpstreamClimate: {
+		// The history is looked up for the location and the days of the forecast
+		requires: []Upstream{UpstreamWeather},
+		fetch:    fetchClimate,
+	},
+	UpstreamAirQuality: {
+		// The air quality is looked up at the weather location, found through the country data
+		requires: []Upstream{UpstreamCountry},
+		fetch:    fetchAirQuality,
+	},
+	UpstreamReferenceRates: {
+		// The base currencies are the currencies of the country
+		requires: []Upstream{UpstreamCountry},
+		fetch:    fetchReferenceRates,
+	},
+	UpstreamRateHistory: {
+		// The history is looked up for the pairs of the fetched rates
+		requires: []Upstream{UpstreamCurrency},
+		fetch:    fetchRateHistory,
+	},
+	UpstreamNeighbours: {
+		// The neighbours are the borders listed in the country data
+		requires: []Upstream{UpstreamCountry},
+		fetch:    fetchNeighbours,
+	},
+}
+
+/*
+RequiredUpstreams Returns the set of upstreams needed by the given features, including the
+upstreams those upstreams require in turn
+*/
+func RequiredUpstreams(providers []FeatureProvider) map[Upstream]bool {
+	needs := make(map[Upstream]bool)
+
+	var add func(upstream Upstream)
+	add = func(upstream Upstream) {
+		if needs[upstream] {
+			return
+		}
+		needs[upstream] = true
+		for _, required := range upstreamSources[upstream].requires {
+			add(required)
+		}
+	}
+
+	for _, provider := range providers {
+		for _, upstream := range provider.Dependencies() {
+			add(upstream)
+		}
+	}
+	return needs
+}
+
+/*
+fetchUpstreams Fetches the needed upstreams in waves. Every upstream whose requirements are
+available is fetched concurrently with the others of its wave, at most config.UpstreamConcurrency at once.
+*/
+func fetchUpstreams(ctx context.Context, reg *utils.Dashboard, needs map[Upstream]bool) (*UpstreamData, error) {
+	data := &UpstreamData{}
+	done := make(map[Upstream]bool)
+
+	for len(done) < len(needs) {
+		// Collect the upstreams that can be fetched now, sorted for a stable error order
+		var wave []Upstream
+		for upstream := range needs {
+			if !done[upstream] && requirementsDone(upstream, done) {
+				wave = append(wave, upstream)
+			}
+		}
+		if len(wave) == 0 {
+			return nil, errors.New("upstream requirements can not be satisfied")
+		}
+		sort.Slice(wave, func(i, j int) bool { return wave[i] < wave[j] })
+
+		errs := make([]error, len(wave))
+		slots := make(chan struct{}, config.UpstreamConcurrency)
+		var wg sync.WaitGroup
+		for i, upstream := range wave {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				errs[i] = upstreamSources[upstream].fetch(ctx, reg, data)
+			}()
+		}
+		wg.Wait()
+
+		for i, upstream := range wave {
+			if errs[i] != nil {
+				return nil, &UpstreamError{Upstream: upstream, Err: errs[i]}
+			}
+			done[upstream] = true
+		}
+	}
+	return data, nil
+}
+
+/*
+requirementsDone Checks if every upstream required by the given upstream has been fetched
+*/
+func requirementsDone(upstream Upstream, done map[Upstream]bool) bool {
+	for _, required := range upstreamSources[upstream].requires {
+		if !done[required] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+fetchCountry Gets the country info from the REST Countries API
+*/
+func fetchCountry(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	countryData, err := clients.GetCountryData(ctx, reg.Country, reg.IsoCode)
+	if err != nil {
+		return err
+	}
+	data.Country = countryData
+	return nil
+}
+
+/*
+fetchWeather Gets the weather forecast at the weather location of the registration from the configured
+weather provider, covering the horizon of every enabled weather feature
+*/
+func fetchWeather(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	location, err := resolveWeatherLocation(reg.WeatherLocation, data.Country)
+	if err != nil {
+		return err
+	}
+	weatherData, err := clients.GetWeatherDate(ctx, location.Latitude, location.Longitude, weatherHorizon(reg.Features))
+	if err != nil {
+		return err
+	}
+	data.Weather = weatherData
+	data.WeatherLocation = location
+	return nil
+}
+
+/*
+fetchCurrency Gets the target currency rates for every base currency concurrently, at most
+config.UpstreamConcurrency at once, and groups them by base currency in currency code order. The base is the
+currency set in the registration, or else every currency of the country.
+*/
+func fetchCurrency(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) error {
+	// The codes are sorted, so the response is assembled in the same order every time
+	currencyCode := baseCurrencies(reg, data.Country)
+	// Check if no currency codes were found
+	if len(currencyCode) == 0 {
+		return errors.New("no currency codes found for country")
+	}
+
+	// Each lookup writes to its own index, keeping the results in currency code order
+	results := make([]*utils.CurrencyAPIResult, len(currencyCode))
+	errs := make([]error, len(currencyCode))
+	slots := make(chan struct{}, config.UpstreamConcurrency)
+	var wg sync.WaitGroup
+	for i, code := range currencyCode {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i], errs[i] = clients.GetCurrencyRates(ctx, reg.Features.Strings("targetCurrencies"), code)
+		}()
+	}
+	wg.Wait()
+
+	groups := []utils.GroupedCurrencyResponse{}
+	for i, result := range results {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		convertAmounts(result.Rates, reg.Currency.Amount)
+
+		groupExists := false
+
+		// Check if group exists, and if exits it becomes an array
+		for j, group := range groups {
+			if group.BaseCode == result.BaseCode {
+				groups[j].Rates = append(groups[j].Rates, result.Rates...)
+				groupExists = true
+				break
+			}
+		}
+
+		//if group dosnt exist create feature dashboard
+		if !groupExists {
+			groups = append(groups, utils.GroupedCurrencyResponse{
+				BaseCode:               result.BaseCode,
+				TimeLastCurrencyUpdate: result.TimeLastUpdateUTC,
+				TimeNextCurrencyUpdate: result.TimeNextUpdateUTC,
+				Amount:                 reg.Currency.Amount,
+				Rates:                  result.Rates,
+			})
+		}
+	}
+
+	data.Currency = groups
+	return nil
+}
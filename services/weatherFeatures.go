@@ -0,0 +1,197 @@
+package services
+
+import (
+	"assignment-2/clients"
+	"assignment-2/config"
+	"assignment-2/utils"
+	"context"
+	"math"
+)
+
+/*
+forecastSchema Describes the options of a weather feature with a configurable forecast horizon
+*/
+func forecastSchema(description string) FeatureSchema {
+	return FeatureSchema{
+		Type:        SchemaObject,
+		Description: description,
+		Properties: map[string]FeatureSchema{
+			"enabled": {Type: SchemaBoolean, Description: "Switches the feature on"},
+			"days": {Type: SchemaInteger, Description: "Forecast horizon in days, 0 for the default",
+				Minimum: 0, Maximum: config.MAX_FORECAST_DAYS},
+		},
+	}
+}
+
+// forecastFeatures are the weather features with a forecast horizon of their own
+var forecastFeatures = []string{"dailyForecast", "temperatureRange", "precipitationSum", "wind", "humidity", "sunriseSunset"}
+
+/*
+forecastDays Returns the forecast horizon of a weather feature, using the default if none is set
+*/
+func forecastDays(features utils.Features, name string) int {
+	if days := features.OptionInt(name, "days"); days > 0 {
+		return days
+	}
+	return config.DEFAULT_FORECAST_DAYS
+}
+
+/*
+weatherHorizon Returns the number of forecast days to fetch, covering the longest enabled weather feature
+*/
+func weatherHorizon(features utils.Features) int {
+	days := config.DEFAULT_FORECAST_DAYS
+	for _, name := range forecastFeatures {
+		if features.OptionBool(name, "enabled") && forecastDays(features, name) > days {
+			days = forecastDays(features, name)
+		}
+	}
+	return days
+}
+
+/*
+roundedOrNil Rounds a value to 2 decimal places, keeping a missing value as nil
+*/
+func roundedOrNil(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := math.Round(*value*100) / 100
+	return &rounded
+}
+
+/*
+init Registers the extended weather features
+*/
+func init() {
+	RegisterFeature(feature{
+		name:         "dailyForecast",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       forecastSchema("Forecast series with every daily value and its date"),
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(forecastDays(reg.Features, "dailyForecast"))
+			return map[string]interface{}{
+				"provider": forecast.Provider,
+				"days":     forecast.Daily,
+			}, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "temperatureRange",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       forecastSchema("Daily minimum and maximum temperature in °C"),
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(forecastDays(reg.Features, "temperatureRange"))
+			type temperatureDay struct {
+				Date string   `json:"date"`
+				Min  *float64 `json:"min"`
+				Max  *float64 `json:"max"`
+			}
+			days := []temperatureDay{}
+			for _, day := range forecast.Daily {
+				days = append(days, temperatureDay{Date: day.Date, Min: day.TemperatureMin, Max: day.TemperatureMax})
+			}
+			return map[string]interface{}{
+				"min":  clients.Extreme(forecast.Values(func(day utils.DailyForecast) *float64 { return day.TemperatureMin }), math.Min),
+				"max":  clients.Extreme(forecast.Values(func(day utils.DailyForecast) *float64 { return day.TemperatureMax }), math.Max),
+				"days": days,
+			}, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "precipitationSum",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       forecastSchema("Daily and total precipitation in mm"),
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(forecastDays(reg.Features, "precipitationSum"))
+			sums := forecast.Values(func(day utils.DailyForecast) *float64 { return day.PrecipitationSum })
+
+			// The total is unknown if the provider gave no sums at all
+			var total *float64
+			if len(sums) > 0 {
+				sum := 0.0
+				for _, value := range sums {
+					sum += value
+				}
+				total = roundedOrNil(&sum)
+			}
+			return map[string]interface{}{
+				"total": total,
+				"days":  forecast.Series(func(day utils.DailyForecast) *float64 { return day.PrecipitationSum }),
+			}, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "wind",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       forecastSchema("Daily maximum wind speed in km/h and dominant wind direction in degrees"),
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(forecastDays(reg.Features, "wind"))
+			type windDay struct {
+				Date      string   `json:"date"`
+				SpeedMax  *float64 `json:"speedMax"`
+				Direction *float64 `json:"direction"`
+			}
+			days := []windDay{}
+			for _, day := range forecast.Daily {
+				days = append(days, windDay{Date: day.Date, SpeedMax: day.WindSpeedMax, Direction: day.WindDirection})
+			}
+			return map[string]interface{}{
+				"speedMax": clients.Extreme(forecast.Values(func(day utils.DailyForecast) *float64 { return day.WindSpeedMax }), math.Max),
+				"days":     days,
+			}, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "humidity",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       forecastSchema("Daily and mean relative humidity in %"),
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(forecastDays(reg.Features, "humidity"))
+			values := forecast.Values(func(day utils.DailyForecast) *float64 { return day.Humidity })
+
+			var mean *float64
+			if len(values) > 0 {
+				average := clients.Average(values)
+				mean = &average
+			}
+			return map[string]interface{}{
+				"mean": mean,
+				"days": forecast.Series(func(day utils.DailyForecast) *float64 { return day.Humidity }),
+			}, nil
+		},
+	})
+
+	RegisterFeature(feature{
+		name:         "sunriseSunset",
+		dependencies: []Upstream{UpstreamWeather},
+		schema:       forecastSchema("Daily sunrise and sunset in local time"),
+		resolve: func(ctx context.Context, reg *utils.Dashboard, data *UpstreamData) (interface{}, error) {
+			forecast := data.Weather.FirstDays(forecastDays(reg.Features, "sunriseSunset"))
+			type sunDay struct {
+				Date    string  `json:"date"`
+				Sunrise *string `json:"sunrise"`
+				Sunset  *string `json:"sunset"`
+			}
+			days := []sunDay{}
+			for _, day := range forecast.Daily {
+				entry := sunDay{Date: day.Date}
+				// Providers without sun times report them as null
+				if day.Sunrise != "" {
+					entry.Sunrise = &day.Sunrise
+				}
+				if day.Sunset != "" {
+					entry.Sunset = &day.Sunset
+				}
+				days = append(days, entry)
+			}
+			return map[string]interface{}{
+				"days": days,
+			}, nil
+		},
+	})
+}
@@ -0,0 +1,54 @@
+package utils
+
+/*
+Bool Returns the value of a boolean feature, false if it is not set
+*/
+func (f Features) Bool(name string) bool {
+	value, _ := f[name].(bool)
+	return value
+}
+
+/*
+Strings Returns the value of a list feature, such as the target currencies, nil if it is not set
+*/
+func (f Features) Strings(name string) []string {
+	switch list := f[name].(type) {
+	case []string:
+		return list
+	case []interface{}:
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+/*
+OptionBool Returns a boolean property of an options feature, such as the enabled of dailyForecast
+*/
+func (f Features) OptionBool(name string, property string) bool {
+	options, _ := f[name].(map[string]interface{})
+	value, _ := options[property].(bool)
+	return value
+}
+
+/*
+OptionInt Returns a whole number property of an options feature, such as the days of dailyForecast, 0 if it is
+not set. Numbers are float64 when decoded from JSON and int64 when read from the database.
+*/
+func (f Features) OptionInt(name string, property string) int {
+	options, _ := f[name].(map[string]interface{})
+	switch value := options[property].(type) {
+	case float64:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
@@ -1,5 +1,7 @@
 package utils
 
+import "time"
+
 type Statusresponse struct {
 	CountriesAPI         int    `firestore:"countriesAPI" json:"countriesAPI"`
 	CurrencyAPI          int    `firestore:"currencyAPI" json:"currencyAPI"`
@@ -12,29 +14,181 @@ type Statusresponse struct {
 }
 
 type DashboardPost struct {
-	Country    string   `firestore:"country" json:"country"`
-	IsoCode    string   `firestore:"isoCode" json:"isoCode"`
-	Features   Features `firestore:"features" json:"features"`
-	LastChange string   `firestore:"lastChange" json:"lastChange"`
+	Country         string          `firestore:"country" json:"country"`
+	IsoCode         string          `firestore:"isoCode" json:"isoCode"`
+	Features        Features        `firestore:"features" json:"features"`
+	WeatherLocation WeatherLocation `firestore:"weatherLocation" json:"weatherLocation"`
+	Points          []NamedPoint    `firestore:"points" json:"points"`
+	Formulas        []Formula       `firestore:"formulas" json:"formulas"`
+	Currency        CurrencySetting `firestore:"currency" json:"currency"`
+	Display         DisplaySetting  `firestore:"display" json:"display"`
+	Type            string          `firestore:"type" json:"type"`
+	Countries       []CountryRef    `firestore:"countries" json:"countries"`
+	Region          RegionSetting   `firestore:"region" json:"region"`
+	LastChange      string          `firestore:"lastChange" json:"lastChange"`
 }
 
 type Dashboard struct {
-	Id         string   `firestore:"id" json:"id"`
-	Country    string   `firestore:"country" json:"country"`
-	IsoCode    string   `firestore:"isoCode" json:"isoCode"`
-	Features   Features `firestore:"features" json:"features"`
-	LastChange string   `firestore:"lastChange" json:"lastChange"`
-}
-type Features struct {
-	Temperature      bool     `firestore:"temperature" json:"temperature"`
-	Precipitation    bool     `firestore:"precipitation" json:"precipitation"`
-	Capital          bool     `firestore:"capital" json:"capital"`
-	Coordinates      bool     `firestore:"coordinates" json:"coordinates"`
-	Population       bool     `firestore:"population" json:"population"`
-	Area             bool     `firestore:"area" json:"area"`
-	TargetCurrencies []string `firestore:"targetCurrencies" json:"targetCurrencies"`
+	Id              string          `firestore:"id" json:"id"`
+	Country         string          `firestore:"country" json:"country"`
+	IsoCode         string          `firestore:"isoCode" json:"isoCode"`
+	Features        Features        `firestore:"features" json:"features"`
+	WeatherLocation WeatherLocation `firestore:"weatherLocation" json:"weatherLocation"`
+	Points          []NamedPoint    `firestore:"points" json:"points"`
+	Formulas        []Formula       `firestore:"formulas" json:"formulas"`
+	Currency        CurrencySetting `firestore:"currency" json:"currency"`
+	Display         DisplaySetting  `firestore:"display" json:"display"`
+	Type            string          `firestore:"type" json:"type"`
+	Countries       []CountryRef    `firestore:"countries" json:"countries"`
+	Region          RegionSetting   `firestore:"region" json:"region"`
+	LastChange      string          `firestore:"lastChange" json:"lastChange"`
+}
+
+// Types of registration
+const (
+	RegistrationCountry    = "country"    // A dashboard of one country, the default
+	RegistrationComparison = "comparison" // The same features for several countries side by side
+	RegistrationRegion     = "region"     // Aggregates over the countries of a region
+)
+
+// RegionSetting defines the countries of a region dashboard by exactly one of a REST Countries region,
+// a REST Countries subregion or a list of ISO codes. The name is only used for display.
+type RegionSetting struct {
+	Name      string   `firestore:"name" json:"name"`
+	Region    string   `firestore:"region" json:"region"`
+	Subregion string   `firestore:"subregion" json:"subregion"`
+	IsoCodes  []string `firestore:"isoCodes" json:"isoCodes"`
+}
+
+// RegionDashboard holds the aggregates over the countries of a region, and their details on drill-down
+type RegionDashboard struct {
+	Type          string            `json:"type"`
+	Name          string            `json:"name"`
+	CountryCount  int               `json:"countryCount"`
+	Aggregates    RegionAggregates  `json:"aggregates"`
+	Countries     []RegionCountry   `json:"countries,omitempty"`
+	Units         string            `json:"units"`
+	Formatted     map[string]string `json:"formatted,omitempty"`
+	LastRetrieval string            `json:"lastRetrieval"`
+}
+
+// RegionAggregates are the totals of a region. The mean temperature is weighted by population and
+// only covers the countries with a forecast.
+type RegionAggregates struct {
+	TotalPopulation int      `json:"totalPopulation"`
+	TotalArea       float64  `json:"totalArea"`       // km² or mi²
+	MeanTemperature *float64 `json:"meanTemperature"` // °C or °F
+	Currencies      []string `json:"currencies"`
+}
+
+// RegionCountry holds the details of one country of a region
+type RegionCountry struct {
+	Country     string   `json:"country"`
+	IsoCode     string   `json:"isoCode"`
+	Population  int      `json:"population"`
+	Area        float64  `json:"area"`
+	Temperature *float64 `json:"temperature"`
+	Currencies  []string `json:"currencies"`
+}
+
+// CountryRef names one of the countries of a comparison, by name or ISO code
+type CountryRef struct {
+	Country string `firestore:"country" json:"country"`
+	IsoCode string `firestore:"isoCode" json:"isoCode"`
+}
+
+// ComparisonDashboard holds the dashboards of the countries of a comparison, keyed by ISO code or name,
+// and the countries ranked by every numeric value, highest first
+type ComparisonDashboard struct {
+	Type          string                         `json:"type"`
+	Countries     map[string]*PopulatedDashboard `json:"countries"`
+	Rankings      map[string][]RankEntry         `json:"rankings"`
+	LastRetrieval string                         `json:"lastRetrieval"`
+}
+
+// RankEntry is the place of one country in the ranking of a value
+type RankEntry struct {
+	Country string  `json:"country"`
+	Value   float64 `json:"value"`
+	Rank    int     `json:"rank"`
+}
+
+// Types of weather location a registration can choose
+const (
+	LocationCentroid    = "centroid"    // Geographic centre of the country, the default
+	LocationCapital     = "capital"     // Coordinates of the capital city
+	LocationCoordinates = "coordinates" // Explicit coordinates given in the registration
+)
+
+// NamedPoint is a labelled location that gets its own weather features in the dashboard
+type NamedPoint struct {
+	Label     string  `firestore:"label" json:"label"`
+	Latitude  float64 `firestore:"latitude" json:"latitude"`
+	Longitude float64 `firestore:"longitude" json:"longitude"`
+}
+
+// CurrencySetting sets the base currency of the target rates, instead of the country currencies, and an
+// amount to convert into every target currency. Both are optional.
+type CurrencySetting struct {
+	Base   string  `firestore:"base" json:"base"`
+	Amount float64 `firestore:"amount" json:"amount"`
+}
+
+// DisplaySetting sets how the values of a dashboard are shown: the unit system, a locale for formatted
+// display strings and a language for the country names. All are optional and can be overridden per request.
+type DisplaySetting struct {
+	Units  string `firestore:"units" json:"units"`   // metric, the default, or imperial
+	Locale string `firestore:"locale" json:"locale"` // Language tag of the display strings, such as de-DE
+	Lang   string `firestore:"lang" json:"lang"`     // REST Countries translation code of the names, such as deu
+}
+
+// Unit systems of the dashboard values
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+// Formula is a user-defined arithmetic expression over the resolved values of a dashboard
+type Formula struct {
+	Name       string `firestore:"name" json:"name"`
+	Expression string `firestore:"expression" json:"expression"`
+}
+
+// PointDashboard holds the resolved weather features of a named point
+type PointDashboard struct {
+	NamedPoint
+	Features map[string]interface{} `json:"features"`
 }
 
+// WeatherLocation is the point where the weather of a dashboard is looked up
+type WeatherLocation struct {
+	Type      string  `firestore:"type" json:"type"`
+	Latitude  float64 `firestore:"latitude" json:"latitude"`
+	Longitude float64 `firestore:"longitude" json:"longitude"`
+}
+
+// PopulatedDashboard is a dashboard filled with the resolved values of its enabled features
+type PopulatedDashboard struct {
+	Country  string                 `json:"country"`
+	IsoCode  string                 `json:"isoCode"`
+	Features map[string]interface{} `json:"features"`
+	// The location the weather features were looked up at, if any
+	WeatherLocation *WeatherLocation `json:"weatherLocation,omitempty"`
+	// The weather features of every named point of the registration
+	Points []PointDashboard `json:"points,omitempty"`
+	// The value of every formula of the registration, null if it could not be computed
+	Formulas map[string]*float64 `json:"formulas,omitempty"`
+	// The unit system of the values, and their display strings by dotted path if a locale is set
+	Units         string            `json:"units"`
+	Formatted     map[string]string `json:"formatted,omitempty"`
+	LastRetrieval string            `json:"lastRetrieval"`
+}
+
+// Features holds the configuration value of every feature enabled in a registration, by feature name. The
+// values are checked against the schema of each feature in the feature registry, so adding a feature needs no
+// change here.
+type Features map[string]interface{}
+
 type Webhook struct {
 	ID      string `firestore:"id" json:"id"`
 	URL     string `firestore:"url" json:"url"`
@@ -51,24 +205,96 @@ type WebhookInvocation struct {
 }
 
 type CountryResponse struct {
-	Population int       `json:"population"`
-	Capital    []string  `json:"capital"`
-	Area       float64   `json:"area"`
-	Latlng     []float64 `json:"latlng"`
-	Cca3       string    `json:"cca3"`
+	Population  int       `json:"population"`
+	Capital     []string  `json:"capital"`
+	Area        float64   `json:"area"`
+	Latlng      []float64 `json:"latlng"`
+	Cca2        string    `json:"cca2"`
+	Cca3        string    `json:"cca3"`
+	CapitalInfo struct {
+		Latlng []float64 `json:"latlng"`
+	} `json:"capitalInfo"`
 	Currencies map[string]struct {
 		Name   string `json:"name"`
 		Symbol string `json:"symbol"`
 	} `json:"currencies"`
+	Name struct {
+		Common   string `json:"common"`
+		Official string `json:"official"`
+	} `json:"name"`
+	Region    string            `json:"region"`
+	Subregion string            `json:"subregion"`
+	Languages map[string]string `json:"languages"` // Language names by ISO 639-3 code
+	Borders   []string          `json:"borders"`   // ISO 3166-1 alpha-3 codes of the neighbouring countries
+	Timezones []string          `json:"timezones"` // UTC offsets, such as UTC+01:00
+	Idd       struct {
+		Root     string   `json:"root"`
+		Suffixes []string `json:"suffixes"`
+	} `json:"idd"`
+	Tld   []string `json:"tld"`
+	Flag  string   `json:"flag"` // Emoji
+	Flags struct {
+		Png string `json:"png"`
+		Svg string `json:"svg"`
+		Alt string `json:"alt"`
+	} `json:"flags"`
+	Car struct {
+		Side string `json:"side"`
+	} `json:"car"`
+	Gini         map[string]float64 `json:"gini"` // Gini index by year
+	UnMember     bool               `json:"unMember"`
+	Translations map[string]struct {
+		Common   string `json:"common"`
+		Official string `json:"official"`
+	} `json:"translations"` // Names by translation code, such as deu
 }
 
+// OpenMeteoresponse is the daily forecast as returned by Open-Meteo, where missing values are null
 type OpenMeteoresponse struct {
 	Daily struct {
-		Temperature   []float64 `json:"temperature_2m_mean"`
-		Precipitation []float64 `json:"precipitation_probability_mean"`
+		Time             []string   `json:"time"`
+		Temperature      []*float64 `json:"temperature_2m_mean"`
+		Precipitation    []*float64 `json:"precipitation_probability_mean"`
+		TemperatureMin   []*float64 `json:"temperature_2m_min"`
+		TemperatureMax   []*float64 `json:"temperature_2m_max"`
+		PrecipitationSum []*float64 `json:"precipitation_sum"`
+		WindSpeedMax     []*float64 `json:"wind_speed_10m_max"`
+		WindDirection    []*float64 `json:"wind_direction_10m_dominant"`
+		Humidity         []*float64 `json:"relative_humidity_2m_mean"`
+		Sunrise          []string   `json:"sunrise"`
+		Sunset           []string   `json:"sunset"`
 	}
 }
 
+// MetNorwayResponse is the part of a MET Norway locationforecast response used by the service
+type MetNorwayResponse struct {
+	Properties struct {
+		Timeseries []struct {
+			Time string `json:"time"`
+			Data struct {
+				Instant struct {
+					Details struct {
+						AirTemperature    *float64 `json:"air_temperature"`
+						WindSpeed         *float64 `json:"wind_speed"`
+						WindFromDirection *float64 `json:"wind_from_direction"`
+						RelativeHumidity  *float64 `json:"relative_humidity"`
+					} `json:"details"`
+				} `json:"instant"`
+				Next1Hours *MetNorwayPeriod `json:"next_1_hours"`
+				Next6Hours *MetNorwayPeriod `json:"next_6_hours"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"properties"`
+}
+
+// MetNorwayPeriod is the forecast for the period following a MET Norway time series entry
+type MetNorwayPeriod struct {
+	Details struct {
+		ProbabilityOfPrecipitation *float64 `json:"probability_of_precipitation"`
+		PrecipitationAmount        *float64 `json:"precipitation_amount"`
+	} `json:"details"`
+}
+
 type CurrencyAPIResult struct {
 	BaseCode          string
 	TimeLastUpdateUTC string
@@ -76,13 +302,93 @@ type CurrencyAPIResult struct {
 	Rates             []CurrencyResponse
 }
 type CurrencyResponse struct {
-	Code string  `json:"code"`
-	Rate float64 `json:"rate"`
+	Code   string   `json:"code"`
+	Rate   float64  `json:"rate"`
+	Amount *float64 `json:"amount,omitempty"` // The converted amount, if an amount was given
+	Source string   `json:"source,omitempty"` // The currency source that supplied the rate
+}
+
+// RateTable holds every exchange rate from one base currency, as delivered by one currency source
+type RateTable struct {
+	Source            string             `json:"source"`
+	BaseCode          string             `json:"base_code"`
+	TimeLastUpdateUTC string             `json:"time_last_update_utc"`
+	TimeNextUpdateUTC string             `json:"time_next_update_utc"`
+	Rates             map[string]float64 `json:"rates"`
+	// Fresh marks a table fetched from an online source just now, rather than read from the cache or a file
+	Fresh bool `json:"-"`
 }
 
 type GroupedCurrencyResponse struct {
 	BaseCode               string             `json:"base_code"`
 	TimeLastCurrencyUpdate string             `json:"time_last_update_utc"`
 	TimeNextCurrencyUpdate string             `json:"time_next_update_utc"`
+	Amount                 float64            `json:"amount,omitempty"` // The amount converted into each rate
 	Rates                  []CurrencyResponse `json:"rates"`
 }
+
+// RatePoint is one observation of an exchange rate, stored in the rate history every time the rate is fetched
+type RatePoint struct {
+	Date      string    `firestore:"date" json:"date"` // UTC date of the fetch, yyyy-mm-dd
+	Rate      float64   `firestore:"rate" json:"rate"`
+	Source    string    `firestore:"source" json:"source,omitempty"`
+	Timestamp time.Time `firestore:"timestamp" json:"timestamp"` // Time of the fetch
+}
+
+// RateHistoryResponse is the rate history of one base/target pair, as returned by /history
+type RateHistoryResponse struct {
+	Base   string      `json:"base"`
+	Target string      `json:"target"`
+	Days   int         `json:"days"`
+	Points []RatePoint `json:"points"`
+}
+
+// ConversionResponse is the conversion of an amount into target currencies, as returned by /convert
+type ConversionResponse struct {
+	From              string             `json:"from"`
+	Amount            float64            `json:"amount"`
+	TimeLastUpdateUTC string             `json:"time_last_update_utc"`
+	TimeNextUpdateUTC string             `json:"time_next_update_utc"`
+	Conversions       []CurrencyResponse `json:"conversions"`
+}
+
+// ChartPanel is one chart of a chart image, with a value of every line for each label
+type ChartPanel struct {
+	Title  string
+	Unit   string
+	Kind   string // ChartKindLine or ChartKindBar
+	Labels []string
+	Lines  []ChartLine
+}
+
+// ChartLine is one named series of a chart panel. A missing value is nil.
+type ChartLine struct {
+	Name   string
+	Values []*float64
+}
+
+// Kinds of chart panel
+const (
+	ChartKindLine = "line"
+	ChartKindBar  = "bar"
+)
+
+// GeoJSONFeatureCollection is a GeoJSON document with one feature for every registration with coordinates
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"` // Always FeatureCollection
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature is the position of a registration with its resolved dashboard values as properties
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"` // Always Feature
+	Id         string                 `json:"id"`
+	Geometry   GeoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONPoint is a GeoJSON point, with its coordinates as longitude then latitude
+type GeoJSONPoint struct {
+	Type        string    `json:"type"` // Always Point
+	Coordinates []float64 `json:"coordinates"`
+}
@@ -0,0 +1,122 @@
+package utils
+
+// WeatherForecast is a daily forecast normalized from the response of any weather provider
+type WeatherForecast struct {
+	Provider  string          `json:"provider"`
+	Latitude  float64         `json:"latitude"`
+	Longitude float64         `json:"longitude"`
+	Daily     []DailyForecast `json:"daily"`
+}
+
+// DailyForecast holds the forecast of one day. Values the provider did not deliver are nil or empty.
+type DailyForecast struct {
+	Date                     string   `json:"date"`
+	Temperature              *float64 `json:"temperature"`              // Mean temperature in °C
+	PrecipitationProbability *float64 `json:"precipitationProbability"` // Mean precipitation probability in %
+	TemperatureMin           *float64 `json:"temperatureMin"`           // °C
+	TemperatureMax           *float64 `json:"temperatureMax"`           // °C
+	PrecipitationSum         *float64 `json:"precipitationSum"`         // mm
+	WindSpeedMax             *float64 `json:"windSpeedMax"`             // km/h
+	WindDirection            *float64 `json:"windDirection"`            // Dominant direction in degrees
+	Humidity                 *float64 `json:"humidity"`                 // Mean relative humidity in %
+	Sunrise                  string   `json:"sunrise,omitempty"`        // Local time, ISO 8601
+	Sunset                   string   `json:"sunset,omitempty"`         // Local time, ISO 8601
+}
+
+// DailyValue is one value of a daily forecast series
+type DailyValue struct {
+	Date  string   `json:"date"`
+	Value *float64 `json:"value"`
+}
+
+/*
+FirstDays Returns a copy of the forecast limited to its first days
+*/
+func (f WeatherForecast) FirstDays(days int) WeatherForecast {
+	if days < len(f.Daily) {
+		f.Daily = f.Daily[:days]
+	}
+	return f
+}
+
+/*
+Temperatures Returns the daily mean temperatures, skipping days without a value
+*/
+func (f WeatherForecast) Temperatures() []float64 {
+	return collectDaily(f.Daily, func(day DailyForecast) *float64 { return day.Temperature })
+}
+
+/*
+PrecipitationProbabilities Returns the daily precipitation probabilities, skipping days without a value
+*/
+func (f WeatherForecast) PrecipitationProbabilities() []float64 {
+	return collectDaily(f.Daily, func(day DailyForecast) *float64 { return day.PrecipitationProbability })
+}
+
+/*
+collectDaily Collects one value of every day that has it
+*/
+func collectDaily(days []DailyForecast, value func(day DailyForecast) *float64) []float64 {
+	values := []float64{}
+	for _, day := range days {
+		if v := value(day); v != nil {
+			values = append(values, *v)
+		}
+	}
+	return values
+}
+
+/*
+Values Returns one value of every day that has it
+*/
+func (f WeatherForecast) Values(value func(day DailyForecast) *float64) []float64 {
+	return collectDaily(f.Daily, value)
+}
+
+/*
+Series Returns one value of every day together with its date, keeping days without a value as nil
+*/
+func (f WeatherForecast) Series(value func(day DailyForecast) *float64) []DailyValue {
+	series := []DailyValue{}
+	for _, day := range f.Daily {
+		series = append(series, DailyValue{Date: day.Date, Value: value(day)})
+	}
+	return series
+}
+
+// ClimateNormal is the historical mean temperature of a calendar window, averaged over past years
+type ClimateNormal struct {
+	StartDate       string   `json:"startDate"` // First day of the window in the current year
+	EndDate         string   `json:"endDate"`   // Last day of the window in the current year
+	Years           int      `json:"years"`
+	Days            int      `json:"days"`            // Number of historical days averaged
+	MeanTemperature *float64 `json:"meanTemperature"` // °C
+}
+
+// ArchiveResponse is the daily history as returned by the Open-Meteo archive API
+type ArchiveResponse struct {
+	Daily struct {
+		Time        []string   `json:"time"`
+		Temperature []*float64 `json:"temperature_2m_mean"`
+	} `json:"daily"`
+}
+
+// AirQuality is the current air quality at a location. Values the API did not deliver are nil.
+type AirQuality struct {
+	Time        string   `json:"time"`        // Local time, ISO 8601
+	PM25        *float64 `json:"pm2_5"`       // μg/m³
+	PM10        *float64 `json:"pm10"`        // μg/m³
+	Ozone       *float64 `json:"ozone"`       // μg/m³
+	EuropeanAQI *float64 `json:"europeanAqi"` // European Air Quality Index, 0 and up
+}
+
+// AirQualityResponse is the current air quality as returned by the Open-Meteo air quality API
+type AirQualityResponse struct {
+	Current struct {
+		Time        string   `json:"time"`
+		PM25        *float64 `json:"pm2_5"`
+		PM10        *float64 `json:"pm10"`
+		Ozone       *float64 `json:"ozone"`
+		EuropeanAQI *float64 `json:"european_aqi"`
+	} `json:"current"`
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	// Embeds the time zone database, so the zones of the table load even where the system has none
+	_ "time/tzdata"
+)
+
+// zoneTable is the tz database table of time zones by country, embedded so no file of the host is needed
+//
+//go:embed zone.tab
+var zoneTable string
+
+/*
+tableZone A zone of the zone table with the position of its principal city, in degrees
+*/
+type tableZone struct {
+	name      string
+	latitude  float64
+	longitude float64
+}
+
+var (
+	// countryZones holds the tz database zones of every country by ISO 3166-1 alpha-2 code, parsed once
+	countryZones map[string][]tableZone
+	zonesOnce    sync.Once
+)
+
+/*
+parseZoneTable Parses the embedded zone table, once
+*/
+func parseZoneTable() {
+	zonesOnce.Do(func() {
+		countryZones = make(map[string][]tableZone)
+		// Every line that is not a comment holds a country code, coordinates and a zone name
+		scanner := bufio.NewScanner(strings.NewReader(zoneTable))
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			zone := tableZone{name: fields[2]}
+			zone.latitude, zone.longitude = parseZoneCoordinates(fields[1])
+			countryZones[fields[0]] = append(countryZones[fields[0]], zone)
+		}
+	})
+}
+
+/*
+parseZoneCoordinates Parses the ISO 6709 coordinates of the zone table, such as +5955+01045 or +404251-0740023,
+into degrees of latitude and longitude
+*/
+func parseZoneCoordinates(coordinates string) (float64, float64) {
+	split := strings.IndexAny(coordinates[1:], "+-") + 1
+	return sexagesimal(coordinates[:split], 2), sexagesimal(coordinates[split:], 3)
+}
+
+/*
+sexagesimal Converts a signed [D]DDMM[SS] number with the given number of degree digits into degrees
+*/
+func sexagesimal(value string, degreeDigits int) float64 {
+	if len(value) < 1+degreeDigits+2 {
+		return 0
+	}
+	sign := 1.0
+	if value[0] == '-' {
+		sign = -1
+	}
+	degrees, _ := strconv.Atoi(value[1 : 1+degreeDigits])
+	minutes, _ := strconv.Atoi(value[1+degreeDigits : 3+degreeDigits])
+	seconds := 0
+	if len(value) >= 5+degreeDigits {
+		seconds, _ = strconv.Atoi(value[3+degreeDigits : 5+degreeDigits])
+	}
+	return sign * (float64(degrees) + float64(minutes)/60 + float64(seconds)/3600)
+}
+
+/*
+ZonesOfCountry Returns the tz database zones of a country, in the order of the zone table
+*/
+func ZonesOfCountry(cca2 string) []string {
+	parseZoneTable()
+	var names []string
+	for _, zone := range countryZones[strings.ToUpper(cca2)] {
+		names = append(names, zone.name)
+	}
+	return names
+}
+
+/*
+NearestZone Returns the tz database zone whose principal city is nearest to a location, as an estimate of the
+time zone of the location. The zone table has no borders, so a location near a border may get its neighbour.
+*/
+func NearestZone(latitude float64, longitude float64) string {
+	parseZoneTable()
+	nearest, shortest := "UTC", math.Inf(1)
+	for _, zones := range countryZones {
+		for _, zone := range zones {
+			if distance := centralAngle(latitude, longitude, zone.latitude, zone.longitude); distance < shortest {
+				nearest, shortest = zone.name, distance
+			}
+		}
+	}
+	return nearest
+}
+
+/*
+centralAngle Returns the angle between two locations seen from the centre of the earth, in radians
+*/
+func centralAngle(latitude1 float64, longitude1 float64, latitude2 float64, longitude2 float64) float64 {
+	phi1, phi2 := latitude1*math.Pi/180, latitude2*math.Pi/180
+	deltaLambda := (longitude2 - longitude1) * math.Pi / 180
+	cosine := math.Sin(phi1)*math.Sin(phi2) + math.Cos(phi1)*math.Cos(phi2)*math.Cos(deltaLambda)
+	return math.Acos(math.Max(-1, math.Min(1, cosine)))
+}